@@ -0,0 +1,158 @@
+// Package awards crowns the foul sport of each season once it is over.
+package awards
+
+import (
+	"fmt"
+	"foulbot/config"
+	"foulbot/data"
+	"foulbot/discord"
+	"foulbot/period"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Run archives every season that has ended once all of its polls have been
+// evaluated, and last year too if no season covers it and it ended within
+// config.AWARDS_GRACE. It then announces every archived season that hasn't
+// been announced in channelId. If roleId is set the role moves from the
+// previous season's winners to the new ones.
+func Run(bot discord.Session, guildId, channelId, roleId string, now time.Time) {
+	for _, season := range data.EndedSeasons(now) {
+		if data.PendingPolls(season.From, season.To) == 0 && data.ArchiveSeason(season.Name, season.From, season.To) {
+			slog.Info("Archived season", "season", season.Name)
+		}
+	}
+	year, _ := period.Around(period.Year, now.AddDate(-1, 0, 0))
+	if now.Sub(year.To) < config.AWARDS_GRACE && data.OverlappingSeasons(year.From, year.To) == 0 && data.PendingPolls(year.From, year.To) == 0 {
+		if data.ArchiveSeason(year.Name, year.From, year.To) {
+			slog.Info("Archived season", "season", year.Name)
+		}
+	}
+
+	for _, season := range data.UnannouncedSeasons() {
+		standings := data.Standings(season.Id)
+		_, err := bot.ChannelMessageSendEmbed(channelId, ceremonyEmbed(guildId, season, standings))
+		if err != nil {
+			slog.Error("Failed to announce season awards", "season", season.Name, "channel", channelId, "err", err)
+			return
+		}
+		if roleId != "" {
+			moveTrophy(bot, guildId, roleId, season, standings)
+		}
+		data.MarkSeasonAnnounced(season.Id)
+	}
+}
+
+// Winners are everyone tied for first with any points.
+func Winners(standings []data.Position) (winners []string) {
+	for _, position := range standings {
+		if position.Rank == 1 && position.Points > 0 {
+			winners = append(winners, position.UserId)
+		}
+	}
+	return winners
+}
+
+func moveTrophy(bot discord.Session, guildId, roleId string, season data.Season, standings []data.Position) {
+	winners := Winners(standings)
+	if previous, ok := data.PreviousSeason(season); ok {
+		for _, userId := range Winners(data.Standings(previous.Id)) {
+			if slices.Contains(winners, userId) {
+				continue
+			}
+			err := bot.GuildMemberRoleRemove(guildId, userId, roleId)
+			if err != nil {
+				slog.Error("Failed to take back trophy role", "season", previous.Name, "user", userId, "err", err)
+			}
+		}
+	}
+	for _, userId := range winners {
+		err := bot.GuildMemberRoleAdd(guildId, userId, roleId)
+		if err != nil {
+			slog.Error("Failed to give trophy role", "season", season.Name, "user", userId, "err", err)
+		}
+	}
+}
+
+func ceremonyEmbed(guildId string, season data.Season, standings []data.Position) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title: fmt.Sprintf("🏆 Foul Sport of %s", season.Name),
+		Color: 0xf1c40f,
+	}
+
+	winners := Winners(standings)
+	switch len(winners) {
+	case 0:
+		embed.Description = fmt.Sprintf("Nobody gained a point in %s. A clean season!", season.Name)
+	case 1:
+		embed.Description = fmt.Sprintf("<@%s> is the foul sport of %s with %s!", winners[0], season.Name, points(standings[0].Points))
+	default:
+		embed.Description = fmt.Sprintf("%s share the title of foul sport of %s with %s each!", mentions(winners), season.Name, points(standings[0].Points))
+	}
+
+	var runnersUp []string
+	for _, position := range standings {
+		if position.Rank == 2 || position.Rank == 3 {
+			medal := map[int]string{2: "🥈", 3: "🥉"}[position.Rank]
+			runnersUp = append(runnersUp, fmt.Sprintf("%s <@%s>: %s", medal, position.UserId, points(position.Points)))
+		}
+	}
+	if len(runnersUp) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Runners-up", Value: strings.Join(runnersUp, "\n")})
+	}
+
+	awards := season.Awards
+	if awards.MostPolls != nil {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Most polls created",
+			Value:  fmt.Sprintf("<@%s> with %d", awards.MostPolls.UserId, awards.MostPolls.Points),
+			Inline: true,
+		})
+	}
+	if poll := awards.Biggest; poll != nil {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Biggest single foul",
+			Value:  fmt.Sprintf("%+d to %s for %s", poll.Points, mentions(poll.GainerIds), link(guildId, poll.PollSummary)),
+			Inline: true,
+		})
+	}
+	if poll := awards.Contested; poll != nil {
+		outcome := map[bool]string{true: "passed", false: "failed"}[poll.Passed.Bool]
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Most contested poll",
+			Value:  fmt.Sprintf("%s, %s 👍 %d 👎 %d", link(guildId, poll.PollSummary), outcome, poll.VotesFor, poll.VotesAgainst),
+			Inline: true,
+		})
+	}
+	return embed
+}
+
+func mentions(userIds []string) string {
+	formatted := make([]string, len(userIds))
+	for i, id := range userIds {
+		formatted[i] = fmt.Sprintf("<@%s>", id)
+	}
+	if len(formatted) <= 1 {
+		return strings.Join(formatted, "")
+	}
+	return strings.Join(formatted[:len(formatted)-1], ", ") + " and " + formatted[len(formatted)-1]
+}
+
+func points(n int64) string {
+	if n == 1 {
+		return "1 point"
+	}
+	return fmt.Sprintf("%d points", n)
+}
+
+// link is the poll's reason linking to its message, if it has one.
+func link(guildId string, poll data.PollSummary) string {
+	if poll.ChannelId == data.ImportChannelId {
+		return poll.Reason
+	}
+	return fmt.Sprintf("[%s](https://discord.com/channels/%s/%s/%s)", poll.Reason, guildId, poll.ChannelId, poll.MessageId)
+}
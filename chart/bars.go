@@ -0,0 +1,97 @@
+package chart
+
+import (
+	"image"
+	"io"
+	"math"
+)
+
+// Bar is a labelled value. Text is shown with the bar instead of the value if
+// set.
+type Bar struct {
+	Label string
+	Value float64
+	Text  string
+}
+
+func (b Bar) text() string {
+	if b.Text != "" {
+		return b.Text
+	}
+	return formatTick(b.Value)
+}
+
+// Bars draws a horizontal bar per entry, top to bottom, as a PNG. It suits
+// rankings with long labels such as names.
+func Bars(w io.Writer, title string, bars []Bar) error {
+	c := newCanvas(title)
+
+	labelWidth, textSpace := 0, 0
+	low, high := 0.0, 0.0
+	for _, bar := range bars {
+		labelWidth = max(labelWidth, textWidth(truncate(bar.Label, 24)))
+		textSpace = max(textSpace, textWidth(bar.text()))
+		low, high = math.Min(low, bar.Value), math.Max(high, bar.Value)
+	}
+	if high == low {
+		high = low + 1
+	}
+	plot := image.Rect(margin+labelWidth+8, margin+2*lineHeight+8, Width-margin-textSpace-8, Height-margin)
+	x := func(value float64) int {
+		return plot.Min.X + int(math.Round((value-low)/(high-low)*float64(plot.Dx())))
+	}
+
+	slot := plot.Dy() / max(len(bars), 1)
+	thickness := min(slot*3/4, 40)
+	for i, bar := range bars {
+		top := plot.Min.Y + i*slot + (slot-thickness)/2
+		middle := top + thickness/2 + 4
+		c.text(plot.Min.X-8, middle, truncate(bar.Label, 24), foreground, right)
+		start, end := x(0), x(bar.Value)
+		c.rect(image.Rect(min(start, end), top, max(start, end), top+thickness), colour(i))
+		c.text(max(start, end)+6, middle, bar.text(), foreground, left)
+	}
+	c.rect(image.Rect(x(0), plot.Min.Y, x(0)+1, plot.Max.Y), gridColour)
+	return c.encode(w)
+}
+
+// Histogram draws a vertical bar per entry, left to right, as a PNG. It suits
+// values over time such as points per month.
+func Histogram(w io.Writer, title string, bars []Bar) error {
+	c := newCanvas(title)
+
+	low, high := 0.0, 0.0
+	for _, bar := range bars {
+		low, high = math.Min(low, bar.Value), math.Max(high, bar.Value)
+	}
+	values, low, high := ticks(low, high, 5)
+	plot := image.Rect(margin+textWidth(formatTick(high))+12, margin+2*lineHeight+8, Width-margin-8, Height-margin-lineHeight-6)
+	y := c.axis(plot, low, high, values)
+
+	slot := plot.Dx() / max(len(bars), 1)
+	thickness := min(slot*3/4, 60)
+	for i, bar := range bars {
+		left := plot.Min.X + i*slot + (slot-thickness)/2
+		start, end := y(0), y(bar.Value)
+		c.rect(image.Rect(left, min(start, end), left+thickness, max(start, end)), colour(0))
+		if bar.Value < 0 {
+			c.text(left+thickness/2, end+lineHeight, bar.text(), foreground, center)
+		} else {
+			c.text(left+thickness/2, end-4, bar.text(), foreground, center)
+		}
+		c.text(left+thickness/2, Height-margin, truncate(bar.Label, slot/7), foreground, center)
+	}
+	return c.encode(w)
+}
+
+// truncate shortens s to n characters, marking the cut with a dot.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if n <= 1 {
+		return string(runes[:max(n, 0)])
+	}
+	return string(runes[:n-1]) + "."
+}
@@ -0,0 +1,187 @@
+// Package chart draws the PNG charts attached to embeds. It only uses a
+// built-in bitmap font, so nothing needs to be installed where the bot runs.
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	Width  = 800
+	Height = 400
+
+	margin     = 16
+	lineHeight = 13
+)
+
+// Colours match Discord's dark theme so charts sit well in an embed.
+var (
+	background = color.RGBA{0x2b, 0x2d, 0x31, 0xff}
+	foreground = color.RGBA{0xdb, 0xde, 0xe1, 0xff}
+	gridColour = color.RGBA{0x41, 0x43, 0x4a, 0xff}
+
+	// palette colours series in order, wrapping around.
+	palette = []color.RGBA{
+		{0x58, 0x65, 0xf2, 0xff},
+		{0xed, 0x42, 0x45, 0xff},
+		{0x57, 0xf2, 0x87, 0xff},
+		{0xfe, 0xe7, 0x5c, 0xff},
+		{0xeb, 0x45, 0x9e, 0xff},
+		{0x3b, 0xa5, 0x5d, 0xff},
+		{0xf0, 0xb2, 0x32, 0xff},
+		{0x00, 0xa8, 0xfc, 0xff},
+	}
+)
+
+type align int
+
+const (
+	left align = iota
+	center
+	right
+)
+
+type canvas struct {
+	img *image.RGBA
+}
+
+func newCanvas(title string) *canvas {
+	c := &canvas{img: image.NewRGBA(image.Rect(0, 0, Width, Height))}
+	draw.Draw(c.img, c.img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	c.text(Width/2, margin+lineHeight, title, foreground, center)
+	return c
+}
+
+func colour(i int) color.RGBA {
+	return palette[i%len(palette)]
+}
+
+// text draws s with its baseline at y, aligned on x.
+func (c *canvas) text(x, y int, s string, col color.Color, a align) {
+	d := font.Drawer{Dst: c.img, Src: image.NewUniform(col), Face: basicfont.Face7x13}
+	switch a {
+	case center:
+		x -= d.MeasureString(s).Round() / 2
+	case right:
+		x -= d.MeasureString(s).Round()
+	}
+	d.Dot = fixed.P(x, y)
+	d.DrawString(s)
+}
+
+func textWidth(s string) int {
+	return font.MeasureString(basicfont.Face7x13, s).Round()
+}
+
+func (c *canvas) rect(r image.Rectangle, col color.Color) {
+	draw.Draw(c.img, r, image.NewUniform(col), image.Point{}, draw.Src)
+}
+
+// line draws a line two pixels wide.
+func (c *canvas) line(x0, y0, x1, y1 int, col color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	e := dx + dy
+	for {
+		c.rect(image.Rect(x0, y0, x0+2, y0+2), col)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		if 2*e >= dy {
+			e += dy
+			x0 += sx
+		}
+		if 2*e <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+// legend lists names with their colours along the top of the plot, returning
+// the y coordinate below it.
+func (c *canvas) legend(names []string) int {
+	x, y := margin, margin+2*lineHeight+4
+	for i, name := range names {
+		w := lineHeight + 4 + textWidth(name) + 16
+		if x+w > Width-margin {
+			x, y = margin, y+lineHeight+4
+		}
+		c.rect(image.Rect(x, y-lineHeight+3, x+lineHeight-2, y+1), colour(i))
+		c.text(x+lineHeight+2, y, name, foreground, left)
+		x += w
+	}
+	return y + 8
+}
+
+// axis draws horizontal grid lines and labels for ticks in plot, which maps
+// values from low to high bottom to top. It returns the y coordinate of a
+// value.
+func (c *canvas) axis(plot image.Rectangle, low, high float64, ticks []float64) func(float64) int {
+	y := func(value float64) int {
+		return plot.Max.Y - int(math.Round((value-low)/(high-low)*float64(plot.Dy())))
+	}
+	for _, tick := range ticks {
+		c.rect(image.Rect(plot.Min.X, y(tick), plot.Max.X, y(tick)+1), gridColour)
+		c.text(plot.Min.X-6, y(tick)+4, formatTick(tick), foreground, right)
+	}
+	return y
+}
+
+func (c *canvas) encode(w io.Writer) error {
+	return png.Encode(w, c.img)
+}
+
+// ticks picks round values covering low to high, about n of them, and returns
+// them with the range they span.
+func ticks(low, high float64, n int) ([]float64, float64, float64) {
+	if high <= low {
+		high = low + 1
+	}
+	raw := (high - low) / float64(n)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude * 10
+	for _, m := range []float64{1, 2, 5} {
+		if m*magnitude >= raw {
+			step = m * magnitude
+			break
+		}
+	}
+	start, end := math.Floor(low/step)*step, math.Ceil(high/step)*step
+	var result []float64
+	for tick := start; tick <= end+step/2; tick += step {
+		result = append(result, tick)
+	}
+	return result, start, end
+}
+
+func formatTick(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func sign(n int) int {
+	if n < 0 {
+		return -1
+	}
+	if n > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,123 @@
+package chart
+
+import (
+	"bytes"
+	"flag"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden images in testdata")
+
+// golden compares a rendered chart pixel by pixel with testdata/name.png,
+// rewriting it instead with -update. Look at the image after updating.
+func golden(t *testing.T, name string, render func(io.Writer) error) {
+	t.Helper()
+	var got bytes.Buffer
+	if err := render(&got); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join("testdata", name+".png")
+	if *update {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("%v, run go test ./chart -update to create it", err)
+	}
+	defer file.Close()
+	want, err := png.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered, err := png.Decode(&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pixels(rendered), pixels(want)) {
+		t.Errorf("%s differs from %s, run go test ./chart -update and check the new image", name, path)
+	}
+}
+
+func pixels(img image.Image) []byte {
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	return rgba.Pix
+}
+
+func TestBars(t *testing.T) {
+	golden(t, "bars", func(w io.Writer) error {
+		return Bars(w, "Leaderboard 2024", []Bar{
+			{Label: "dave", Value: 42},
+			{Label: "erin", Value: 30},
+			{Label: "someone with a very long name", Value: 12},
+			{Label: "frank", Value: -3},
+			{Label: "grace", Value: 0, Text: "0%"},
+		})
+	})
+}
+
+func TestLine(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(1, 0, 0)
+	day := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	golden(t, "line", func(w io.Writer) error {
+		return Line(w, "Points race 2024", from, to, []Series{
+			Cumulative("dave", from, to, []Point{{day(2, 3), 5}, {day(5, 1), 2}, {day(9, 12), 9}}),
+			Cumulative("erin", from, to, []Point{{day(3, 3), 3}, {day(7, 1), 12}}),
+			Cumulative("frank", from, to, nil),
+		})
+	})
+}
+
+func TestHistogram(t *testing.T) {
+	golden(t, "histogram", func(w io.Writer) error {
+		return Histogram(w, "dave, points by month", []Bar{
+			{Label: "2024-01", Value: 5},
+			{Label: "2024-02", Value: 0},
+			{Label: "2024-03", Value: 12},
+			{Label: "2024-04", Value: -2},
+			{Label: "2024-05", Value: 7},
+		})
+	})
+}
+
+func TestCumulative(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	series := Cumulative("dave", from, to, []Point{{from.AddDate(0, 0, 1), 2}, {from.AddDate(0, 0, 3), 5}})
+	want := []float64{0, 0, 2, 2, 7, 7}
+	if len(series.Points) != len(want) {
+		t.Fatalf("expected %d points, got %+v", len(want), series.Points)
+	}
+	for i, point := range series.Points {
+		if point.Value != want[i] {
+			t.Errorf("point %d is %v, want %v", i, point.Value, want[i])
+		}
+	}
+	if !series.Points[len(want)-1].Time.Equal(to) {
+		t.Errorf("expected the series to run to %s, got %s", to, series.Points[len(want)-1].Time)
+	}
+}
+
+func TestTicks(t *testing.T) {
+	values, low, high := ticks(-3, 42, 5)
+	if low != -10 || high != 50 || len(values) != 7 {
+		t.Errorf("ticks(-3, 42, 5) = %v from %v to %v", values, low, high)
+	}
+}
@@ -0,0 +1,111 @@
+package chart
+
+import (
+	"image"
+	"io"
+	"math"
+	"time"
+)
+
+// Point is a value at a time.
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Series is a named line, with points in time order.
+type Series struct {
+	Name   string
+	Points []Point
+}
+
+// Cumulative turns events into a running total that steps up at each event,
+// starting at zero at from and carrying the total on to to.
+func Cumulative(name string, from, to time.Time, events []Point) Series {
+	series := Series{Name: name, Points: []Point{{Time: from}}}
+	total := 0.0
+	for _, event := range events {
+		series.Points = append(series.Points, Point{Time: event.Time, Value: total})
+		total += event.Value
+		series.Points = append(series.Points, Point{Time: event.Time, Value: total})
+	}
+	series.Points = append(series.Points, Point{Time: to, Value: total})
+	return series
+}
+
+// Line draws series between from and to as a PNG.
+func Line(w io.Writer, title string, from, to time.Time, series []Series) error {
+	c := newCanvas(title)
+	names := make([]string, len(series))
+	low, high := 0.0, 0.0
+	for i, s := range series {
+		names[i] = s.Name
+		for _, point := range s.Points {
+			low, high = math.Min(low, point.Value), math.Max(high, point.Value)
+		}
+	}
+	top := c.legend(names)
+
+	values, low, high := ticks(low, high, 5)
+	plot := image.Rect(margin+textWidth(formatTick(high))+12, top+8, Width-margin-8, Height-margin-lineHeight-6)
+	y := c.axis(plot, low, high, values)
+
+	if !to.After(from) {
+		to = from.Add(time.Hour)
+	}
+	x := func(t time.Time) int {
+		return plot.Min.X + int(math.Round(float64(t.Sub(from))/float64(to.Sub(from))*float64(plot.Dx())))
+	}
+	for _, t := range timeTicks(from, to) {
+		c.text(x(t), Height-margin, t.Format(timeLayout(from, to)), foreground, center)
+	}
+
+	for i, s := range series {
+		for j := 1; j < len(s.Points); j++ {
+			a, b := s.Points[j-1], s.Points[j]
+			c.line(x(a.Time), y(a.Value), x(b.Time), y(b.Value), colour(i))
+		}
+	}
+	return c.encode(w)
+}
+
+// timeTicks are the starts of the days, months or years between from and to,
+// whichever gives a readable number of labels.
+func timeTicks(from, to time.Time) []time.Time {
+	var step func(time.Time) time.Time
+	var start time.Time
+	year, month, day := from.Date()
+	switch span := to.Sub(from); {
+	case span <= 14*24*time.Hour:
+		start, step = time.Date(year, month, day, 0, 0, 0, 0, from.Location()), func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	case span <= 400*24*time.Hour:
+		start, step = time.Date(year, month, 1, 0, 0, 0, 0, from.Location()), func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	default:
+		start, step = time.Date(year, 1, 1, 0, 0, 0, 0, from.Location()), func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }
+	}
+	var result []time.Time
+	for t := start; t.Before(to); t = step(t) {
+		if !t.Before(from) {
+			result = append(result, t)
+		}
+	}
+	// Keep at most about a dozen labels
+	if every := (len(result)-1)/12 + 1; every > 1 {
+		var thinned []time.Time
+		for i := 0; i < len(result); i += every {
+			thinned = append(thinned, result[i])
+		}
+		result = thinned
+	}
+	return result
+}
+
+func timeLayout(from, to time.Time) string {
+	switch span := to.Sub(from); {
+	case span <= 14*24*time.Hour:
+		return "Jan 2"
+	case span <= 400*24*time.Hour:
+		return "Jan"
+	}
+	return "2006"
+}
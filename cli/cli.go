@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"foulbot/config"
+	"foulbot/data"
+	"foulbot/export"
+	"foulbot/importer"
+	"foulbot/updater"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const usage = `usage: foulbot [command]
+
+Run without a command to start the bot.
+
+commands:
+  restore <backup.zip>    replace the database with a backup from /logs
+  export [flags]          write poll history as CSV or JSON
+      -format csv|json    output format (default csv)
+      -year YYYY          only polls that closed in this year
+      -user ID            only polls created by or gained by this user
+      -o FILE             write to FILE instead of stdout
+  import [flags] <file>   add historical polls from a CSV or JSON file
+      -dry-run            only show what would be imported
+      -format csv|json    input format (default from the file extension)
+      -creator ID         user id recorded as the creator of imported polls
+  keygen                  write a release signing key to release.key and release.pub
+  sign <key> <version> <binary>...
+                          write <binary>.sha256 and <binary>.sig for a release`
+
+// Run executes an offline subcommand and returns the process exit code.
+func Run(args []string) int {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		cfg = config.Default()
+	}
+
+	switch args[0] {
+	case "restore", "export", "import":
+		data.Open()
+	}
+
+	switch args[0] {
+	case "restore":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, usage)
+			return 2
+		}
+		safety, err := data.Restore(args[1], cfg.BackupDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "restore failed: %s\n", err)
+			return 1
+		}
+		fmt.Printf("Restored %s (previous database saved to %s)\n", args[1], safety)
+	case "export":
+		flags := flag.NewFlagSet("export", flag.ContinueOnError)
+		format := flags.String("format", "csv", "")
+		year := flags.String("year", "", "")
+		user := flags.String("user", "", "")
+		output := flags.String("o", "", "")
+		flags.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+
+		var w io.Writer = os.Stdout
+		if *output != "" {
+			f, err := os.Create(*output)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "export failed: %s\n", err)
+				return 1
+			}
+			defer f.Close()
+			w = f
+		}
+		if err := export.Write(w, *format, *year, *user); err != nil {
+			fmt.Fprintf(os.Stderr, "export failed: %s\n", err)
+			return 1
+		}
+	case "import":
+		flags := flag.NewFlagSet("import", flag.ContinueOnError)
+		dryRun := flags.Bool("dry-run", false, "")
+		format := flags.String("format", "", "")
+		creator := flags.String("creator", "", "")
+		flags.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+		if flags.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, usage)
+			return 2
+		}
+		path := flags.Arg(0)
+		if *format == "" {
+			*format = importer.FormatFromName(path)
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "import failed: %s\n", err)
+			return 1
+		}
+		defer f.Close()
+		polls, err := importer.Parse(f, *format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "import failed: %s\n", err)
+			return 1
+		}
+
+		if cfg.DiscordToken == "" {
+			fmt.Fprintln(os.Stderr, "warning: no discord_token configured, gainers are not checked against the guild")
+		} else {
+			session, err := discordgo.New("Bot " + cfg.DiscordToken)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "import failed: %s\n", err)
+				return 1
+			}
+			unknown := importer.UnknownUsers(polls, func(userId string) bool {
+				_, err := session.GuildMember(cfg.DiscordGuildID, userId)
+				return err == nil
+			})
+			if len(unknown) > 0 {
+				fmt.Fprintf(os.Stderr, "import failed: not guild members: %s\n", strings.Join(unknown, ", "))
+				return 1
+			}
+		}
+
+		fmt.Print(importer.Preview(polls))
+		if *dryRun {
+			return 0
+		}
+		inserted, err := data.ImportPolls(*creator, polls)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "import failed: %s\n", err)
+			return 1
+		}
+		fmt.Printf("Imported %d new polls (%d already present)\n", inserted, len(polls)-inserted)
+	case "keygen":
+		publicKey, privateKey, err := ed25519.GenerateKey(nil)
+		if err == nil {
+			err = os.WriteFile("release.key", []byte(base64.StdEncoding.EncodeToString(privateKey)+"\n"), 0o600)
+		}
+		if err == nil {
+			err = os.WriteFile("release.pub", []byte(base64.StdEncoding.EncodeToString(publicKey)+"\n"), 0o644)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "keygen failed: %s\n", err)
+			return 1
+		}
+		fmt.Println("Wrote release.key and release.pub. Keep release.key secret.")
+	case "sign":
+		if len(args) < 4 {
+			fmt.Fprintln(os.Stderr, usage)
+			return 2
+		}
+		encoded, err := os.ReadFile(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "sign failed: %s\n", err)
+			return 1
+		}
+		privateKey, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+		if err != nil || len(privateKey) != ed25519.PrivateKeySize {
+			fmt.Fprintln(os.Stderr, "sign failed: not a release key")
+			return 1
+		}
+		version := args[2]
+		for _, path := range args[3:] {
+			binary, err := os.ReadFile(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "sign failed: %s\n", err)
+				return 1
+			}
+			checksum, signature := updater.Sign(privateKey, version, filepath.Base(path), binary)
+			err = os.WriteFile(path+".sha256", checksum, 0o644)
+			if err == nil {
+				err = os.WriteFile(path+".sig", signature, 0o644)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "sign failed: %s\n", err)
+				return 1
+			}
+		}
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+	return 0
+}
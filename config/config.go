@@ -12,12 +12,85 @@ var (
 	POLL_LENGTH = 16 * time.Hour
 	NUMBERS     = []string{":one:", ":two:", ":three:", ":four:", ":five:",
 		":six:", ":seven:", ":eight:", ":nine:", ":keycap_ten:"}
+	// PAGE_SIZE is how many lines a paginated embed shows at a time.
+	PAGE_SIZE = 10
+)
+
+var (
+	// UPDATE_PUBLIC_KEY is the base64 ed25519 key release binaries are signed
+	// with. It is set at build time from release.pub.
+	UPDATE_PUBLIC_KEY string
+	// RELEASES_URL is the default releases_url. Releases are expected in the
+	// GitHub layout: latest/download/<asset> and download/<version>/<asset>.
+	RELEASES_URL = "https://github.com/mustafa-tariqk/foulbot/releases"
+	// UPDATE_HEALTH_TIMEOUT is how long a freshly updated binary has to
+	// connect before it is rolled back.
+	UPDATE_HEALTH_TIMEOUT = time.Minute
+	// SHUTDOWN_TIMEOUT bounds how long shutdown waits for in-flight commands
+	// and poll evaluation.
+	SHUTDOWN_TIMEOUT = 10 * time.Second
+	// Failed outbox entries are retried after OUTBOX_BASE_DELAY, doubling up
+	// to OUTBOX_MAX_DELAY, until OUTBOX_MAX_ATTEMPTS.
+	OUTBOX_BASE_DELAY   = time.Minute
+	OUTBOX_MAX_DELAY    = time.Hour
+	OUTBOX_MAX_ATTEMPTS = 10
+	// LOG_FILES_IN_ZIP is how many of the newest log files /logs uploads.
+	LOG_FILES_IN_ZIP = 5
+	// RATE_MIN_SAMPLE is how many polls or votes someone needs before they
+	// appear on the pass rate, contrarian and lenient leaderboards.
+	RATE_MIN_SAMPLE = 3
+	// STATUS_LIST_LENGTH is how many recent polls, co-gainers and reasons
+	// /status lists.
+	STATUS_LIST_LENGTH = 5
+	// RACE_SIZE is how many of the top members the leaderboard race chart
+	// follows.
+	RACE_SIZE = 5
+	// AWARDS_GRACE is how long after the end of a year its ceremony can still
+	// be held, so enabling awards late doesn't crown a long finished year.
+	AWARDS_GRACE = 14 * 24 * time.Hour
 )
 
 type Config struct {
 	DiscordToken   string `json:"discord_token"`
 	DiscordGuildID string `json:"discord_guild_id"`
 	DiscordAppID   string `json:"discord_application_id"`
+
+	BackupDir           string `json:"backup_dir"`
+	BackupIntervalHours int    `json:"backup_interval_hours"`
+	BackupRetention     int    `json:"backup_retention"`
+
+	AdminUserIDs []string `json:"admin_user_ids"`
+	AdminRoleIDs []string `json:"admin_role_ids"`
+
+	ReleasesURL           string `json:"releases_url"`
+	UpdateNoticeChannelID string `json:"update_notice_channel_id"`
+
+	MetricsAddr    string `json:"metrics_addr"`
+	ErrorChannelID string `json:"error_channel_id"`
+
+	AwardsChannelID string `json:"awards_channel_id"`
+	TrophyRoleID    string `json:"trophy_role_id"`
+
+	LogLevel      string `json:"log_level"`
+	LogFormat     string `json:"log_format"`
+	LogDir        string `json:"log_dir"`
+	LogMaxSizeMB  int    `json:"log_max_size_mb"`
+	LogMaxAgeDays int    `json:"log_max_age_days"`
+}
+
+// Default returns a Config with only the optional settings filled in.
+func Default() *Config {
+	return &Config{
+		BackupDir:           "backups",
+		BackupIntervalHours: 24,
+		BackupRetention:     7,
+		ReleasesURL:         RELEASES_URL,
+		LogLevel:            "info",
+		LogFormat:           "text",
+		LogDir:              "logs",
+		LogMaxSizeMB:        10,
+		LogMaxAgeDays:       14,
+	}
 }
 
 func LoadConfig() (*Config, error) {
@@ -26,10 +99,10 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
-	var config Config
-	if err := json.Unmarshal(configData, &config); err != nil {
+	config := Default()
+	if err := json.Unmarshal(configData, config); err != nil {
 		return nil, err
 	}
 
-	return &config, nil
+	return config, nil
 }
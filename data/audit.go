@@ -0,0 +1,18 @@
+package data
+
+import (
+	_ "embed"
+	"time"
+)
+
+//go:embed queries/insert_audit.sql
+var insertAuditQuery string
+
+// Audit records an attempt to run a privileged command, whether or not it was
+// allowed.
+func Audit(userId string, command string, options string, allowed bool) {
+	_, err := db.Exec(insertAuditQuery, time.Now().Format(time.RFC3339), userId, command, options, allowed)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,100 @@
+package data
+
+import (
+	"archive/zip"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed queries/backup.sql
+var backupQuery string
+
+const backupPrefix = "foulbot-"
+const backupSuffix = ".sqlite"
+
+// Backup writes a consistent snapshot of the database to path. Unlike copying
+// foulbot.sqlite directly, VACUUM INTO includes committed pages that are still
+// sitting in the WAL file.
+func Backup(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	_, err := db.Exec(backupQuery, path)
+	return err
+}
+
+// RotateBackups takes a timestamped snapshot in dir and deletes the oldest
+// snapshots so that at most retain remain. It returns the new snapshot's path.
+func RotateBackups(dir string, retain int) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, backupPrefix+time.Now().Format("20060102T150405")+backupSuffix)
+	if err := Backup(path); err != nil {
+		return "", err
+	}
+
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return path, err
+	}
+	if retain > 0 && len(backups) > retain {
+		for _, old := range backups[:len(backups)-retain] {
+			if err := os.Remove(old); err != nil {
+				return path, err
+			}
+		}
+	}
+	return path, nil
+}
+
+// ListBackups returns the snapshots in dir, oldest first.
+func ListBackups(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, backupPrefix) && strings.HasSuffix(name, backupSuffix) {
+			backups = append(backups, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// AddBackupToZip snapshots the database and stores it in zw as foulbot.sqlite.
+func AddBackupToZip(zw *zip.Writer) error {
+	tmpDir, err := os.MkdirTemp("", "foulbot-backup-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	snapshot := filepath.Join(tmpDir, "foulbot.sqlite")
+	if err := Backup(snapshot); err != nil {
+		return fmt.Errorf("failed to snapshot database: %v", err)
+	}
+
+	src, err := os.Open(snapshot)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := zw.Create("foulbot.sqlite")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
@@ -0,0 +1,81 @@
+package data
+
+import (
+	_ "embed"
+	"time"
+)
+
+//go:embed queries/board_creators.sql
+var boardCreatorsQuery string
+
+//go:embed queries/board_pass_rate.sql
+var boardPassRateQuery string
+
+//go:embed queries/board_votes.sql
+var boardVotesQuery string
+
+//go:embed queries/board_contrarian.sql
+var boardContrarianQuery string
+
+//go:embed queries/board_lenient.sql
+var boardLenientQuery string
+
+const (
+	// BoardPoints ranks gainers by points, like Leaderboard.
+	BoardPoints = "points"
+	// BoardCreators ranks members by how many polls they created.
+	BoardCreators = "creators"
+	// BoardPassRate ranks creators by the share of their polls that passed.
+	BoardPassRate = "pass-rate"
+	// BoardVotes ranks members by how many votes they cast.
+	BoardVotes = "votes"
+	// BoardContrarian ranks voters by the share of their votes that went
+	// against the poll's outcome.
+	BoardContrarian = "contrarian"
+	// BoardLenient ranks voters by the share of their votes that were yes.
+	BoardLenient = "lenient"
+)
+
+// BOARDS are the rankings /leaderboard offers, in the order they are listed.
+var BOARDS = []string{BoardPoints, BoardCreators, BoardPassRate, BoardVotes, BoardContrarian, BoardLenient}
+
+// IsRate reports whether board ranks by Points out of Of rather than by
+// Points alone.
+func IsRate(board string) bool {
+	return board == BoardPassRate || board == BoardContrarian || board == BoardLenient
+}
+
+// Board ranks members on one of BOARDS for polls that expired between from
+// (inclusive) and to (exclusive). Rate boards leave out anyone with fewer
+// than minSample polls or votes.
+func Board(board string, from, to time.Time, minSample int) (podium []Position) {
+	query := map[string]string{
+		BoardCreators:   boardCreatorsQuery,
+		BoardPassRate:   boardPassRateQuery,
+		BoardVotes:      boardVotesQuery,
+		BoardContrarian: boardContrarianQuery,
+		BoardLenient:    boardLenientQuery,
+	}[board]
+	if query == "" {
+		return Leaderboard(from, to)
+	}
+
+	args := []any{from.Unix(), to.Unix()}
+	if IsRate(board) {
+		args = append(args, minSample)
+	}
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var position Position
+		err = rows.Scan(&position.UserId, &position.Points, &position.Of, &position.Rank)
+		if err != nil {
+			panic(err)
+		}
+		podium = append(podium, position)
+	}
+	return podium
+}
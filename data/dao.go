@@ -2,7 +2,10 @@ package data
 
 import (
 	"database/sql"
-	_ "embed"
+	"embed"
+	"fmt"
+	"sort"
+	"time"
 
 	_ "modernc.org/sqlite"
 )
@@ -10,6 +13,12 @@ import (
 //go:embed queries/make_tables.sql
 var makeTablesQuery string
 
+//go:embed queries/schema_version.sql
+var schemaVersionQuery string
+
+//go:embed queries/migrations/*.sql
+var migrations embed.FS
+
 //go:embed queries/insert_poll.sql
 var insertPollQuery string
 
@@ -34,8 +43,11 @@ var finalizePollQuery string
 //go:embed queries/collect_gainers.sql
 var collectGainersQuery string
 
-//go:embed queries/status.sql
-var statusQuery string
+//go:embed queries/evaluated_poll.sql
+var evaluatedPollQuery string
+
+//go:embed queries/mark_announced.sql
+var markAnnouncedQuery string
 
 var db *sql.DB
 var err error
@@ -63,15 +75,47 @@ type EvaluatedPoll struct {
 	Expiry       string
 }
 
+// Position is a row of a leaderboard. Points is what it is ranked by:
+// points gained, polls created or votes cast, out of Of on rate boards.
 type Position struct {
 	UserId string
 	Points int64
+	Of     int64
+	// Rank is shared by tied positions, so 1, 2, 2, 4.
+	Rank int
 }
 
+// dbPath is the live database file, relative to the working directory.
+const dbPath = "foulbot.sqlite"
+
+// SchemaVersion is stored in PRAGMA user_version. make_tables.sql is version
+// 1 and every file in queries/migrations adds one. Backups newer than this
+// cannot be restored by this build.
+var SchemaVersion int
+
 func init() {
-	var err error
+	entries, err := migrations.ReadDir("queries/migrations")
+	if err != nil {
+		panic(err)
+	}
+	SchemaVersion = 1 + len(entries)
+}
+
+// Open opens the live database, creating and migrating it as needed. A restore
+// staged by StageRestore is put in place first.
+func Open() {
+	applyStagedRestore(dbPath)
+	db = open(dbPath)
+}
+
+// OpenMemory opens a named, shared in-memory database instead, for tests.
+func OpenMemory(name string) {
+	db = openDSN(`file:` + name + `?mode=memory&cache=shared&_foreign_keys=ON`)
+}
+
+func open(path string) *sql.DB {
 	// https://briandouglas.ie/sqlite-defaults/
-	db, err = sql.Open("sqlite", `file:foulbot.sqlite?
+	return openDSN(`file:` + path + `?
             _journal_mode=WAL&
             _synchronous=NORMAL&
             _busy_timeout=5000&
@@ -81,6 +125,10 @@ func init() {
             _temp_store=MEMORY&
             _mmap_size=2147483648&
             _page_size=8192`)
+}
+
+func openDSN(dsn string) *sql.DB {
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		panic(err)
 	}
@@ -89,6 +137,68 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	migrate(db)
+	return db
+}
+
+// migrate applies every migration newer than the database's user_version, in
+// file name order. Each file runs in its own transaction together with the
+// user_version it brings the database to, so a failed migration leaves the
+// earlier ones recorded and is retried alone on the next start.
+func migrate(db *sql.DB) {
+	var version int
+	err := db.QueryRow(schemaVersionQuery).Scan(&version)
+	if err != nil {
+		panic(err)
+	}
+	if version == 0 {
+		version = 1
+	}
+
+	entries, err := migrations.ReadDir("queries/migrations")
+	if err != nil {
+		panic(err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for i, entry := range entries {
+		target := i + 2
+		if target <= version {
+			continue
+		}
+		migration, err := migrations.ReadFile("queries/migrations/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		err = migrateTo(db, target, string(migration))
+		if err != nil {
+			panic(fmt.Errorf("migration %s: %v", entry.Name(), err))
+		}
+	}
+}
+
+func migrateTo(db *sql.DB, version int, migration string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(migration)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d;", version))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Ping checks that the database is reachable.
+func Ping() error {
+	return db.Ping()
 }
 
 func CreatePoll(poll Poll) {
@@ -129,109 +239,111 @@ func ExpiredPolls() (polls []Poll) {
 	return polls
 }
 
-func EvaluatePolls() (polls []EvaluatedPoll) {
-	expiredRows, err := db.Query(expiredRowsQuery)
-	if err != nil {
-		panic(err)
-	}
-	defer expiredRows.Close()
-	for expiredRows.Next() {
-		var poll EvaluatedPoll
-		err = expiredRows.Scan(&poll.MessageId, &poll.ChannelId, &poll.CreatorId, &poll.Points, &poll.Reason, &poll.Expiry)
+// EvaluatePolls records whether each expired poll passed and, in the same
+// transaction, queues the result announcement and button removal in the
+// outbox so they survive a crash. It returns how many passed and failed.
+func EvaluatePolls() (passed int, failed int) {
+	for _, poll := range ExpiredPolls() {
+		evaluated := EvaluatedPoll{ChannelId: poll.ChannelId, MessageId: poll.MessageId}
+		collectVotes(&evaluated)
+		evaluated.Passed = len(evaluated.VotesFor) > len(evaluated.VotesAgainst)
+
+		tx, err := db.Begin()
 		if err != nil {
 			panic(err)
 		}
-		polls = append(polls, poll)
-	}
-
-	for i := range polls {
-		poll := &polls[i]
-		votesForRows, err := db.Query(collectVotesQuery, poll.ChannelId, poll.MessageId, 1)
+		_, err = tx.Exec(finalizePollQuery, evaluated.Passed, poll.ChannelId, poll.MessageId)
 		if err != nil {
+			tx.Rollback()
 			panic(err)
 		}
-		defer votesForRows.Close()
-		for votesForRows.Next() {
-			var voterId string
-			err = votesForRows.Scan(&voterId)
-			if err != nil {
-				panic(err)
-			}
-			poll.VotesFor = append(poll.VotesFor, voterId)
-		}
-
-		votesAgainstRows, err := db.Query(collectVotesQuery, poll.ChannelId, poll.MessageId, 0)
+		payload := OutboxPayload{ChannelId: poll.ChannelId, MessageId: poll.MessageId}
+		enqueue(tx.Exec, OutboxResult, payload)
+		enqueue(tx.Exec, OutboxButtons, payload)
+		err = tx.Commit()
 		if err != nil {
 			panic(err)
 		}
-		defer votesAgainstRows.Close()
-		for votesAgainstRows.Next() {
-			var voterId string
-			err = votesAgainstRows.Scan(&voterId)
-			if err != nil {
-				panic(err)
-			}
-			poll.VotesAgainst = append(poll.VotesAgainst, voterId)
-		}
 
-		if len(poll.VotesFor) > len(poll.VotesAgainst) {
-			poll.Passed = true
+		if evaluated.Passed {
+			passed++
 		} else {
-			poll.Passed = false
+			failed++
 		}
+	}
+	return passed, failed
+}
 
-		rows, err := db.Query(collectGainersQuery, poll.ChannelId, poll.MessageId)
-		if err != nil {
-			panic(err)
-		}
-		defer rows.Close()
-		for rows.Next() {
-			var gainerId string
-			err = rows.Scan(&gainerId)
-			if err != nil {
-				panic(err)
-			}
-			poll.GainerIds = append(poll.GainerIds, gainerId)
-		}
+// EvaluatedPollById returns a finalized poll with its votes and gainers.
+func EvaluatedPollById(channelId, messageId string) (poll EvaluatedPoll, ok bool) {
+	rows, err := db.Query(evaluatedPollQuery, channelId, messageId)
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return poll, false
+	}
+	err = rows.Scan(&poll.MessageId, &poll.ChannelId, &poll.CreatorId, &poll.Points, &poll.Reason, &poll.Expiry, &poll.Passed)
+	if err != nil {
+		panic(err)
+	}
+	rows.Close()
 
-		_, err = db.Exec(finalizePollQuery, poll.Passed, poll.ChannelId, poll.MessageId)
-		if err != nil {
-			panic(err)
-		}
+	collectVotes(&poll)
+	collectGainers(&poll)
+	return poll, true
+}
+
+func MarkAnnounced(channelId, messageId string) {
+	_, err = db.Exec(markAnnouncedQuery, channelId, messageId)
+	if err != nil {
+		panic(err)
 	}
-	return polls
 }
 
-func Leaderboard(year string) (podium []Position) {
-	rows, err := db.Query(leaderboardQuery, year)
+func collectVotes(poll *EvaluatedPoll) {
+	poll.VotesFor = collectIds(collectVotesQuery, poll.ChannelId, poll.MessageId, 1)
+	poll.VotesAgainst = collectIds(collectVotesQuery, poll.ChannelId, poll.MessageId, 0)
+}
+
+func collectGainers(poll *EvaluatedPoll) {
+	poll.GainerIds = collectIds(collectGainersQuery, poll.ChannelId, poll.MessageId)
+}
 
+func collectIds(query string, args ...any) (ids []string) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		panic(err)
 	}
 	defer rows.Close()
 	for rows.Next() {
-		var position Position
-		err = rows.Scan(&position.UserId, &position.Points)
+		var id string
+		err = rows.Scan(&id)
 		if err != nil {
 			panic(err)
 		}
-		podium = append(podium, position)
+		ids = append(ids, id)
 	}
-	return podium
+	return ids
 }
 
-func Status(userId string, year string) (points int64) {
-	rows, err := db.Query(statusQuery, userId, year)
+// Leaderboard totals the points of polls that passed and expired between from
+// (inclusive) and to (exclusive).
+func Leaderboard(from, to time.Time) (podium []Position) {
+	rows, err := db.Query(leaderboardQuery, from.Unix(), to.Unix())
+
 	if err != nil {
 		panic(err)
 	}
 	defer rows.Close()
 	for rows.Next() {
-		// remove "var points int64"
-		err = rows.Scan(&points)
+		var position Position
+		err = rows.Scan(&position.UserId, &position.Points, &position.Rank)
 		if err != nil {
 			panic(err)
 		}
+		podium = append(podium, position)
 	}
-	return points
+	return podium
 }
@@ -0,0 +1,57 @@
+package data
+
+import (
+	"database/sql"
+	_ "embed"
+	"strings"
+	"time"
+)
+
+//go:embed queries/export_polls.sql
+var exportPollsQuery string
+
+type ExportedPoll struct {
+	ChannelId    string   `json:"channel_id"`
+	MessageId    string   `json:"message_id"`
+	CreatorId    string   `json:"creator_id"`
+	Points       int64    `json:"points"`
+	Reason       string   `json:"reason"`
+	Expiry       string   `json:"expiry"`
+	Passed       *bool    `json:"passed"`
+	Imported     bool     `json:"imported"`
+	GainerIds    []string `json:"gainer_ids"`
+	VotesFor     []string `json:"votes_for"`
+	VotesAgainst []string `json:"votes_against"`
+}
+
+// ExportPolls calls fn for every poll that expired between from (inclusive)
+// and to (exclusive), in expiry order, without loading the whole history into
+// memory. An empty userId matches everyone; otherwise it matches polls the user
+// created or gained in. Pending polls have a nil Passed.
+func ExportPolls(from, to time.Time, userId string, fn func(ExportedPoll) error) error {
+	rows, err := db.Query(exportPollsQuery, from.Unix(), to.Unix(), userId)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var poll ExportedPoll
+		var passed sql.NullBool
+		var gainers, votesFor, votesAgainst string
+		err = rows.Scan(&poll.ChannelId, &poll.MessageId, &poll.CreatorId, &poll.Points, &poll.Reason, &poll.Expiry,
+			&passed, &poll.Imported, &gainers, &votesFor, &votesAgainst)
+		if err != nil {
+			return err
+		}
+		if passed.Valid {
+			poll.Passed = &passed.Bool
+		}
+		poll.GainerIds = strings.Fields(gainers)
+		poll.VotesFor = strings.Fields(votesFor)
+		poll.VotesAgainst = strings.Fields(votesAgainst)
+		if err := fn(poll); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
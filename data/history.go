@@ -0,0 +1,120 @@
+package data
+
+import (
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+//go:embed queries/history.sql
+var historyQuery string
+
+//go:embed queries/save_search.sql
+var saveSearchQuery string
+
+//go:embed queries/load_search.sql
+var loadSearchQuery string
+
+//go:embed queries/prune_searches.sql
+var pruneSearchesQuery string
+
+// Poll outcomes HistoryFilter can select.
+const (
+	OutcomePassed  = "passed"
+	OutcomeFailed  = "failed"
+	OutcomePending = "pending"
+)
+
+// HistoryFilter selects polls that expired between From (inclusive) and To
+// (exclusive). Empty fields match everything.
+type HistoryFilter struct {
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	GainerId  string    `json:"gainer_id,omitempty"`
+	CreatorId string    `json:"creator_id,omitempty"`
+	Outcome   string    `json:"outcome,omitempty"`
+	Text      string    `json:"text,omitempty"` // found anywhere in the reason
+}
+
+type HistoryPoll struct {
+	PollSummary
+	GainerIds    []string
+	VotesFor     int
+	VotesAgainst int
+}
+
+// History returns a page of the polls matching filter, newest first, and how
+// many match in total.
+func History(filter HistoryFilter, offset, limit int) (polls []HistoryPoll, total int) {
+	rows, err := db.Query(historyQuery,
+		sql.Named("from", filter.From.Unix()),
+		sql.Named("to", filter.To.Unix()),
+		sql.Named("gainer", filter.GainerId),
+		sql.Named("creator", filter.CreatorId),
+		sql.Named("outcome", filter.Outcome),
+		sql.Named("text", escapeLike(filter.Text)),
+		sql.Named("limit", limit),
+		sql.Named("offset", offset))
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var poll HistoryPoll
+		var gainers sql.NullString
+		err = rows.Scan(&poll.ChannelId, &poll.MessageId, &poll.CreatorId, &poll.Points, &poll.Reason, &poll.Expiry, &poll.Passed,
+			&gainers, &poll.VotesFor, &poll.VotesAgainst, &total)
+		if err != nil {
+			panic(err)
+		}
+		poll.GainerIds = strings.Fields(gainers.String)
+		polls = append(polls, poll)
+	}
+	return polls, total
+}
+
+// SaveSearch stores filter so paginated results can find it again from a
+// button, returning its id. Searches older than a month are pruned.
+func SaveSearch(kind string, filter any) int64 {
+	encoded, err := json.Marshal(filter)
+	if err != nil {
+		panic(err)
+	}
+	_, err = db.Exec(pruneSearchesQuery, "-30 days")
+	if err != nil {
+		panic(err)
+	}
+	result, err := db.Exec(saveSearchQuery, kind, string(encoded))
+	if err != nil {
+		panic(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// LoadSearch reads a saved search of kind into filter. It returns false if
+// the search does not exist or was pruned.
+func LoadSearch(id int64, kind string, filter any) bool {
+	var encoded string
+	err := db.QueryRow(loadSearchQuery, id, kind).Scan(&encoded)
+	if err == sql.ErrNoRows {
+		return false
+	}
+	if err != nil {
+		panic(err)
+	}
+	err = json.Unmarshal([]byte(encoded), filter)
+	if err != nil {
+		panic(err)
+	}
+	return true
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
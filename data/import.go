@@ -0,0 +1,67 @@
+package data
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"fmt"
+	"strings"
+	"time"
+)
+
+//go:embed queries/import_poll.sql
+var importPollQuery string
+
+//go:embed queries/import_gainer.sql
+var importGainerQuery string
+
+// ImportChannelId is the channel_id of polls that came from /import rather
+// than a Discord message.
+const ImportChannelId = "import"
+
+type ImportedPoll struct {
+	Date      time.Time
+	GainerIds []string
+	Points    int64
+	Reason    string
+	Passed    bool
+}
+
+// MessageId derives a stable id from the row's contents so importing the same
+// file twice does not duplicate history.
+func (poll ImportedPoll) MessageId() string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d|%s",
+		poll.Date.Format(time.RFC3339), strings.Join(poll.GainerIds, " "), poll.Points, poll.Reason)))
+	return fmt.Sprintf("import-%x", sum[:8])
+}
+
+// ImportPolls inserts already finalized polls in one transaction and returns
+// how many were new.
+func ImportPolls(creatorId string, polls []ImportedPoll) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	inserted := 0
+	for _, poll := range polls {
+		messageId := poll.MessageId()
+		result, err := tx.Exec(importPollQuery, ImportChannelId, messageId, creatorId, poll.Points, poll.Reason,
+			poll.Date.Format(time.RFC3339), poll.Passed)
+		if err != nil {
+			return 0, err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			continue
+		}
+		inserted++
+
+		for _, gainerId := range poll.GainerIds {
+			_, err = tx.Exec(importGainerQuery, ImportChannelId, messageId, gainerId)
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+	return inserted, tx.Commit()
+}
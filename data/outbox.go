@@ -0,0 +1,151 @@
+package data
+
+import (
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+//go:embed queries/enqueue_outbox.sql
+var enqueueOutboxQuery string
+
+//go:embed queries/due_outbox.sql
+var dueOutboxQuery string
+
+//go:embed queries/dead_outbox.sql
+var deadOutboxQuery string
+
+//go:embed queries/complete_outbox.sql
+var completeOutboxQuery string
+
+//go:embed queries/fail_outbox.sql
+var failOutboxQuery string
+
+//go:embed queries/retry_outbox.sql
+var retryOutboxQuery string
+
+//go:embed queries/claim_outbox.sql
+var claimOutboxQuery string
+
+// Outbox kinds are the Discord side effects of finishing a poll.
+const (
+	OutboxResult  = "result"  // post the result embed
+	OutboxThread  = "thread"  // start a thread on the posted result
+	OutboxButtons = "buttons" // remove the vote buttons from the poll
+)
+
+type OutboxPayload struct {
+	ChannelId       string `json:"channel_id"`
+	MessageId       string `json:"message_id"`
+	ResultMessageId string `json:"result_message_id,omitempty"`
+}
+
+type OutboxEntry struct {
+	Id        int64
+	Key       string
+	Kind      string
+	Payload   OutboxPayload
+	Attempts  int
+	LastError string
+	// Claimed is set once an attempt has started, so an earlier attempt may
+	// have taken effect even though the entry was never completed.
+	Claimed   bool
+	CreatedAt string
+}
+
+// outboxTime formats times so they compare correctly as text.
+func outboxTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Enqueue adds a side effect to the outbox. Entries are identified by kind and
+// the poll's channel and message, so enqueueing the same effect twice is a
+// no-op.
+func Enqueue(kind string, payload OutboxPayload) {
+	enqueue(db.Exec, kind, payload)
+}
+
+// enqueue takes db.Exec or tx.Exec so entries can be added in the same
+// transaction as the change that caused them.
+func enqueue(exec func(string, ...any) (sql.Result, error), kind string, payload OutboxPayload) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		panic(err)
+	}
+	key := fmt.Sprintf("%s:%s:%s", kind, payload.ChannelId, payload.MessageId)
+	now := outboxTime(time.Now())
+	_, err = exec(enqueueOutboxQuery, key, kind, string(encoded), now, now)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// DueOutbox returns pending entries whose next attempt is at or before now.
+func DueOutbox(now time.Time) []OutboxEntry {
+	return queryOutbox(dueOutboxQuery, outboxTime(now))
+}
+
+// DeadOutbox returns entries that ran out of attempts, newest first.
+func DeadOutbox() []OutboxEntry {
+	return queryOutbox(deadOutboxQuery)
+}
+
+// ClaimOutbox records that an attempt at id is about to start.
+func ClaimOutbox(id int64) {
+	_, err = db.Exec(claimOutboxQuery, id)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func CompleteOutbox(id int64) {
+	_, err = db.Exec(completeOutboxQuery, id)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// FailOutbox records a failed attempt, scheduling the next one for retryAt or
+// moving the entry to the dead letters if dead is set.
+func FailOutbox(id int64, cause error, retryAt time.Time, dead bool) {
+	status := map[bool]string{true: "dead", false: "pending"}[dead]
+	_, err = db.Exec(failOutboxQuery, status, outboxTime(retryAt), cause.Error(), id)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// RetryOutbox moves a dead entry back to pending. It reports whether id was a
+// dead entry.
+func RetryOutbox(id int64) bool {
+	result, err := db.Exec(retryOutboxQuery, outboxTime(time.Now()), id)
+	if err != nil {
+		panic(err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0
+}
+
+func queryOutbox(query string, args ...any) (entries []OutboxEntry) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var entry OutboxEntry
+		var payload string
+		err = rows.Scan(&entry.Id, &entry.Key, &entry.Kind, &payload, &entry.Attempts, &entry.LastError, &entry.Claimed, &entry.CreatedAt)
+		if err != nil {
+			panic(err)
+		}
+		err = json.Unmarshal([]byte(payload), &entry.Payload)
+		if err != nil {
+			panic(err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -0,0 +1,7 @@
+UPDATE seasons
+SET
+    awards = ?,
+    archived_at = strftime ('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE
+    id = ?
+    AND archived_at IS NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+VACUUM INTO ?;
@@ -0,0 +1,19 @@
+SELECT
+    p.creator_id,
+    COUNT(*) AS created,
+    0,
+    RANK() OVER (
+        ORDER BY
+            COUNT(*) DESC
+    ) AS rank
+FROM
+    polls p
+WHERE
+    unixepoch (p.expiry) >= ?
+    AND unixepoch (p.expiry) < ?
+    AND p.imported = 0
+GROUP BY
+    p.creator_id
+ORDER BY
+    created DESC,
+    p.creator_id;
\ No newline at end of file
@@ -0,0 +1,24 @@
+SELECT
+    v.user_id,
+    SUM(v.value = 1) AS yes,
+    COUNT(*) AS voted,
+    RANK() OVER (
+        ORDER BY
+            1.0 * SUM(v.value = 1) / COUNT(*) DESC
+    ) AS rank
+FROM
+    votes v
+    JOIN polls p ON p.channel_id = v.channel_id
+    AND p.message_id = v.message_id
+WHERE
+    unixepoch (p.expiry) >= ?
+    AND unixepoch (p.expiry) < ?
+    AND p.passed IS NOT NULL
+GROUP BY
+    v.user_id
+HAVING
+    COUNT(*) >= ?
+ORDER BY
+    rank,
+    voted DESC,
+    v.user_id;
\ No newline at end of file
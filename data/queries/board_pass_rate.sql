@@ -0,0 +1,23 @@
+SELECT
+    p.creator_id,
+    SUM(p.passed) AS passed,
+    COUNT(*) AS finished,
+    RANK() OVER (
+        ORDER BY
+            1.0 * SUM(p.passed) / COUNT(*) DESC
+    ) AS rank
+FROM
+    polls p
+WHERE
+    unixepoch (p.expiry) >= ?
+    AND unixepoch (p.expiry) < ?
+    AND p.imported = 0
+    AND p.passed IS NOT NULL
+GROUP BY
+    p.creator_id
+HAVING
+    COUNT(*) >= ?
+ORDER BY
+    rank,
+    finished DESC,
+    p.creator_id;
\ No newline at end of file
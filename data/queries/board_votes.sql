@@ -0,0 +1,20 @@
+SELECT
+    v.user_id,
+    COUNT(*) AS voted,
+    0,
+    RANK() OVER (
+        ORDER BY
+            COUNT(*) DESC
+    ) AS rank
+FROM
+    votes v
+    JOIN polls p ON p.channel_id = v.channel_id
+    AND p.message_id = v.message_id
+WHERE
+    unixepoch (p.expiry) >= ?
+    AND unixepoch (p.expiry) < ?
+GROUP BY
+    v.user_id
+ORDER BY
+    voted DESC,
+    v.user_id;
\ No newline at end of file
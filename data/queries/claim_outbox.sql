@@ -0,0 +1,5 @@
+UPDATE outbox
+SET
+    claimed = 1
+WHERE
+    id = ?;
\ No newline at end of file
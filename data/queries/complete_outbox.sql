@@ -0,0 +1,7 @@
+UPDATE outbox
+SET
+    status = 'done',
+    attempts = attempts + 1,
+    last_error = ''
+WHERE
+    id = ?;
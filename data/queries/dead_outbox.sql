@@ -0,0 +1,15 @@
+SELECT
+    id,
+    idempotency_key,
+    kind,
+    payload,
+    attempts,
+    last_error,
+    claimed,
+    created_at
+FROM
+    outbox
+WHERE
+    status = 'dead'
+ORDER BY
+    id DESC;
@@ -0,0 +1,16 @@
+SELECT
+    id,
+    idempotency_key,
+    kind,
+    payload,
+    attempts,
+    last_error,
+    claimed,
+    created_at
+FROM
+    outbox
+WHERE
+    status = 'pending'
+    AND next_attempt <= ?
+ORDER BY
+    id;
@@ -0,0 +1,6 @@
+UPDATE seasons
+SET
+    ends_at = ?
+WHERE
+    id = ?
+    AND archived_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,4 @@
+INSERT
+OR IGNORE INTO outbox (idempotency_key, kind, payload, next_attempt, created_at)
+VALUES
+    (?, ?, ?, ?, ?);
@@ -0,0 +1,14 @@
+SELECT
+    message_id,
+    channel_id,
+    creator_id,
+    points,
+    reason,
+    expiry,
+    passed
+FROM
+    polls
+WHERE
+    channel_id = ?
+    AND message_id = ?
+    AND passed IS NOT NULL;
@@ -0,0 +1,69 @@
+SELECT
+    p.channel_id,
+    p.message_id,
+    p.creator_id,
+    p.points,
+    p.reason,
+    p.expiry,
+    p.passed,
+    p.imported,
+    COALESCE(
+        (
+            SELECT
+                group_concat (g.user_id, ' ')
+            FROM
+                gainers g
+            WHERE
+                g.channel_id = p.channel_id
+                AND g.message_id = p.message_id
+        ),
+        ''
+    ) AS gainer_ids,
+    COALESCE(
+        (
+            SELECT
+                group_concat (v.user_id, ' ')
+            FROM
+                votes v
+            WHERE
+                v.channel_id = p.channel_id
+                AND v.message_id = p.message_id
+                AND v.value = 1
+        ),
+        ''
+    ) AS votes_for,
+    COALESCE(
+        (
+            SELECT
+                group_concat (v.user_id, ' ')
+            FROM
+                votes v
+            WHERE
+                v.channel_id = p.channel_id
+                AND v.message_id = p.message_id
+                AND v.value = 0
+        ),
+        ''
+    ) AS votes_against
+FROM
+    polls p
+WHERE
+    unixepoch (p.expiry) >= ?1
+    AND unixepoch (p.expiry) < ?2
+    AND (
+        ?3 = ''
+        OR p.creator_id = ?3
+        OR EXISTS (
+            SELECT
+                1
+            FROM
+                gainers g
+            WHERE
+                g.channel_id = p.channel_id
+                AND g.message_id = p.message_id
+                AND g.user_id = ?3
+        )
+    )
+ORDER BY
+    unixepoch (p.expiry),
+    p.rowid;
\ No newline at end of file
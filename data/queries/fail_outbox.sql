@@ -0,0 +1,8 @@
+UPDATE outbox
+SET
+    status = ?,
+    attempts = attempts + 1,
+    next_attempt = ?,
+    last_error = ?
+WHERE
+    id = ?;
@@ -0,0 +1,86 @@
+SELECT
+    p.channel_id,
+    p.message_id,
+    p.creator_id,
+    p.points,
+    p.reason,
+    p.expiry,
+    p.passed,
+    (
+        SELECT
+            group_concat (g.user_id, ' ')
+        FROM
+            gainers g
+        WHERE
+            g.channel_id = p.channel_id
+            AND g.message_id = p.message_id
+    ),
+    (
+        SELECT
+            COUNT(*)
+        FROM
+            votes v
+        WHERE
+            v.channel_id = p.channel_id
+            AND v.message_id = p.message_id
+            AND v.value = 1
+    ),
+    (
+        SELECT
+            COUNT(*)
+        FROM
+            votes v
+        WHERE
+            v.channel_id = p.channel_id
+            AND v.message_id = p.message_id
+            AND v.value = 0
+    ),
+    COUNT(*) OVER ()
+FROM
+    polls p
+WHERE
+    unixepoch (p.expiry) >= :from
+    AND unixepoch (p.expiry) < :to
+    AND (
+        :gainer = ''
+        OR EXISTS (
+            SELECT
+                1
+            FROM
+                gainers g
+            WHERE
+                g.channel_id = p.channel_id
+                AND g.message_id = p.message_id
+                AND g.user_id = :gainer
+        )
+    )
+    AND (
+        :creator = ''
+        OR p.creator_id = :creator
+    )
+    AND (
+        :outcome = ''
+        OR (
+            :outcome = 'passed'
+            AND p.passed = 1
+        )
+        OR (
+            :outcome = 'failed'
+            AND p.passed = 0
+        )
+        OR (
+            :outcome = 'pending'
+            AND p.passed IS NULL
+        )
+    )
+    AND (
+        :text = ''
+        OR p.reason LIKE '%' || :text || '%' ESCAPE '\'
+    )
+ORDER BY
+    unixepoch (p.expiry) DESC,
+    p.message_id
+LIMIT
+    :limit
+OFFSET
+    :offset;
\ No newline at end of file
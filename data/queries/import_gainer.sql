@@ -0,0 +1,4 @@
+INSERT
+OR IGNORE INTO gainers (channel_id, message_id, user_id)
+VALUES
+    (?, ?, ?);
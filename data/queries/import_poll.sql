@@ -0,0 +1,13 @@
+INSERT
+OR IGNORE INTO polls (
+    channel_id,
+    message_id,
+    creator_id,
+    points,
+    reason,
+    expiry,
+    passed,
+    imported
+)
+VALUES
+    (?, ?, ?, ?, ?, ?, ?, 1);
@@ -0,0 +1,4 @@
+INSERT INTO
+    audit_log (created_at, user_id, command, options, allowed)
+VALUES
+    (?, ?, ?, ?, ?);
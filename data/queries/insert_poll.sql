@@ -6,7 +6,8 @@ INSERT INTO
         points,
         reason,
         expiry,
-        passed
+        passed,
+        announced
     )
 VALUES
-    (?, ?, ?, ?, ?, ?, NULL);
+    (?, ?, ?, ?, ?, ?, NULL, 0);
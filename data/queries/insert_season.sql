@@ -0,0 +1,4 @@
+INSERT
+OR IGNORE INTO seasons (name, starts_at, ends_at)
+VALUES
+    (?, ?, ?);
\ No newline at end of file
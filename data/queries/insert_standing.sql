@@ -0,0 +1,4 @@
+INSERT INTO
+    season_standings (season_id, user_id, rank, points)
+VALUES
+    (?, ?, ?, ?);
\ No newline at end of file
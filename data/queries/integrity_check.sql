@@ -0,0 +1 @@
+PRAGMA integrity_check;
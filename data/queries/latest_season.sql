@@ -0,0 +1,14 @@
+SELECT
+    id,
+    name,
+    starts_at,
+    ends_at,
+    awards,
+    archived_at IS NOT NULL,
+    announced
+FROM
+    seasons
+ORDER BY
+    unixepoch (starts_at) DESC
+LIMIT
+    1;
\ No newline at end of file
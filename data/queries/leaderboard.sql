@@ -1,13 +1,19 @@
 SELECT
     g.user_id,
-    SUM(p.points) as total_points
+    SUM(p.points) as total_points,
+    RANK() OVER (
+        ORDER BY
+            SUM(p.points) DESC
+    ) as rank
 FROM
     polls p
     JOIN gainers g ON p.message_id = g.message_id
 WHERE
-    strftime ('%Y', p.expiry) = ?
+    unixepoch (p.expiry) >= ?
+    AND unixepoch (p.expiry) < ?
     AND p.passed = 1
 GROUP BY
     g.user_id
 ORDER BY
-    total_points DESC;
+    total_points DESC,
+    g.user_id;
\ No newline at end of file
@@ -0,0 +1,7 @@
+SELECT
+    filter
+FROM
+    searches
+WHERE
+    id = ?
+    AND kind = ?;
\ No newline at end of file
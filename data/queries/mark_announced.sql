@@ -0,0 +1,6 @@
+UPDATE polls
+SET
+    announced = 1
+WHERE
+    channel_id = ?
+    AND message_id = ?;
@@ -0,0 +1,5 @@
+UPDATE seasons
+SET
+    announced = 1
+WHERE
+    id = ?;
\ No newline at end of file
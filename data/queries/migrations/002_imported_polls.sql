@@ -0,0 +1,2 @@
+ALTER TABLE polls
+ADD COLUMN imported INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS "audit_log" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" TEXT NOT NULL,
+    "user_id" TEXT NOT NULL,
+    "command" TEXT NOT NULL,
+    "options" TEXT NOT NULL,
+    "allowed" INTEGER NOT NULL
+);
@@ -0,0 +1,2 @@
+ALTER TABLE polls
+ADD COLUMN announced INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS "outbox" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "idempotency_key" TEXT NOT NULL UNIQUE,
+    "kind" TEXT NOT NULL,
+    "payload" TEXT NOT NULL,
+    "status" TEXT NOT NULL DEFAULT 'pending',
+    "attempts" INTEGER NOT NULL DEFAULT 0,
+    "next_attempt" TEXT NOT NULL,
+    "last_error" TEXT NOT NULL DEFAULT '',
+    "created_at" TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS "outbox_due" ON "outbox" ("status", "next_attempt");
+
+INSERT
+OR IGNORE INTO outbox (idempotency_key, kind, payload, next_attempt, created_at)
+SELECT
+    kind || ':' || channel_id || ':' || message_id,
+    kind,
+    json_object ('channel_id', channel_id, 'message_id', message_id),
+    strftime ('%Y-%m-%dT%H:%M:%SZ', 'now'),
+    strftime ('%Y-%m-%dT%H:%M:%SZ', 'now')
+FROM
+    polls,
+    (
+        SELECT
+            'result' AS kind
+        UNION ALL
+        SELECT
+            'buttons'
+    )
+WHERE
+    passed IS NOT NULL
+    AND announced = 0;
@@ -0,0 +1,12 @@
+CREATE INDEX IF NOT EXISTS "polls_expiry" ON "polls" (unixepoch ("expiry"));
+
+CREATE INDEX IF NOT EXISTS "polls_creator" ON "polls" ("creator_id", unixepoch ("expiry"));
+
+CREATE INDEX IF NOT EXISTS "gainers_user" ON "gainers" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "searches" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "kind" TEXT NOT NULL,
+    "filter" TEXT NOT NULL,
+    "created_at" TEXT NOT NULL
+);
\ No newline at end of file
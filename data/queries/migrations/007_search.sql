@@ -0,0 +1,45 @@
+CREATE VIRTUAL TABLE IF NOT EXISTS "polls_search" USING fts5 (
+    "reason",
+    "channel_id" UNINDEXED,
+    "message_id" UNINDEXED,
+    tokenize = 'porter unicode61 remove_diacritics 2'
+);
+
+INSERT INTO
+    polls_search (reason, channel_id, message_id)
+SELECT
+    reason,
+    channel_id,
+    message_id
+FROM
+    polls;
+
+CREATE TRIGGER IF NOT EXISTS "polls_search_insert" AFTER INSERT ON "polls" BEGIN
+INSERT INTO
+    polls_search (reason, channel_id, message_id)
+VALUES
+    (new.reason, new.channel_id, new.message_id);
+
+END;
+
+CREATE TRIGGER IF NOT EXISTS "polls_search_update" AFTER
+UPDATE OF "reason" ON "polls" BEGIN
+DELETE FROM polls_search
+WHERE
+    channel_id = old.channel_id
+    AND message_id = old.message_id;
+
+INSERT INTO
+    polls_search (reason, channel_id, message_id)
+VALUES
+    (new.reason, new.channel_id, new.message_id);
+
+END;
+
+CREATE TRIGGER IF NOT EXISTS "polls_search_delete" AFTER DELETE ON "polls" BEGIN
+DELETE FROM polls_search
+WHERE
+    channel_id = old.channel_id
+    AND message_id = old.message_id;
+
+END;
\ No newline at end of file
@@ -0,0 +1,48 @@
+CREATE TABLE IF NOT EXISTS "seasons" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "name" TEXT NOT NULL UNIQUE,
+    "starts_at" TEXT NOT NULL,
+    "ends_at" TEXT NOT NULL,
+    "awards" TEXT,
+    "archived_at" TEXT,
+    "announced" INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS "season_standings" (
+    "season_id" INTEGER NOT NULL,
+    "user_id" TEXT NOT NULL,
+    "rank" INTEGER NOT NULL,
+    "points" INTEGER NOT NULL,
+    PRIMARY KEY ("season_id", "user_id"),
+    FOREIGN KEY ("season_id") REFERENCES "seasons" ("id")
+);
+
+CREATE TRIGGER IF NOT EXISTS "season_standings_immutable" BEFORE
+UPDATE ON "season_standings" BEGIN
+SELECT
+    RAISE (ABORT, 'archived standings are immutable');
+
+END;
+
+CREATE TRIGGER IF NOT EXISTS "season_standings_undeletable" BEFORE DELETE ON "season_standings" BEGIN
+SELECT
+    RAISE (ABORT, 'archived standings are immutable');
+
+END;
+
+CREATE TRIGGER IF NOT EXISTS "seasons_archived_immutable" BEFORE
+UPDATE OF "name",
+"starts_at",
+"ends_at",
+"awards",
+"archived_at" ON "seasons" WHEN old.archived_at IS NOT NULL BEGIN
+SELECT
+    RAISE (ABORT, 'archived seasons are immutable');
+
+END;
+
+CREATE TRIGGER IF NOT EXISTS "seasons_archived_undeletable" BEFORE DELETE ON "seasons" WHEN old.archived_at IS NOT NULL BEGIN
+SELECT
+    RAISE (ABORT, 'archived seasons are immutable');
+
+END;
\ No newline at end of file
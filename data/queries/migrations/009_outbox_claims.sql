@@ -0,0 +1,2 @@
+ALTER TABLE outbox
+ADD COLUMN claimed INTEGER NOT NULL DEFAULT 0;
\ No newline at end of file
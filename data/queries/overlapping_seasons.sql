@@ -0,0 +1,7 @@
+SELECT
+    COUNT(*)
+FROM
+    seasons
+WHERE
+    unixepoch (starts_at) < ?
+    AND unixepoch (ends_at) > ?;
\ No newline at end of file
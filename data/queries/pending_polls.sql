@@ -0,0 +1,8 @@
+SELECT
+    COUNT(*)
+FROM
+    polls
+WHERE
+    passed IS NULL
+    AND unixepoch (expiry) >= ?
+    AND unixepoch (expiry) < ?;
\ No newline at end of file
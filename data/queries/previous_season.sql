@@ -0,0 +1,18 @@
+SELECT
+    id,
+    name,
+    starts_at,
+    ends_at,
+    awards,
+    archived_at IS NOT NULL,
+    announced
+FROM
+    seasons
+WHERE
+    archived_at IS NOT NULL
+    AND unixepoch (ends_at) <= unixepoch (?)
+    AND id != ?
+ORDER BY
+    unixepoch (ends_at) DESC
+LIMIT
+    1;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DELETE FROM searches
+WHERE
+    created_at < strftime ('%Y-%m-%dT%H:%M:%SZ', 'now', ?);
\ No newline at end of file
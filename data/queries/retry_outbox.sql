@@ -0,0 +1,8 @@
+UPDATE outbox
+SET
+    status = 'pending',
+    attempts = 0,
+    next_attempt = ?
+WHERE
+    id = ?
+    AND status = 'dead';
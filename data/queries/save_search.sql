@@ -0,0 +1,4 @@
+INSERT INTO
+    searches (kind, filter, created_at)
+VALUES
+    (?, ?, strftime ('%Y-%m-%dT%H:%M:%SZ', 'now'));
\ No newline at end of file
@@ -0,0 +1,36 @@
+WITH
+    matches AS MATERIALIZED (
+        SELECT
+            channel_id,
+            message_id,
+            snippet (polls_search, 0, '**', '**', '…', 16) AS snippet,
+            rank
+        FROM
+            polls_search
+        WHERE
+            polls_search MATCH :query
+    )
+SELECT
+    p.channel_id,
+    p.message_id,
+    p.creator_id,
+    p.points,
+    p.reason,
+    p.expiry,
+    p.passed,
+    m.snippet,
+    COUNT(*) OVER ()
+FROM
+    matches m
+    JOIN polls p ON p.channel_id = m.channel_id
+    AND p.message_id = m.message_id
+WHERE
+    unixepoch (p.expiry) >= :from
+    AND unixepoch (p.expiry) < :to
+ORDER BY
+    m.rank,
+    unixepoch (p.expiry) DESC
+LIMIT
+    :limit
+OFFSET
+    :offset;
\ No newline at end of file
@@ -0,0 +1,48 @@
+SELECT
+    p.channel_id,
+    p.message_id,
+    p.creator_id,
+    p.points,
+    p.reason,
+    p.expiry,
+    p.passed,
+    (
+        SELECT
+            group_concat (g.user_id, ' ')
+        FROM
+            gainers g
+        WHERE
+            g.channel_id = p.channel_id
+            AND g.message_id = p.message_id
+    ),
+    (
+        SELECT
+            COUNT(*)
+        FROM
+            votes v
+        WHERE
+            v.channel_id = p.channel_id
+            AND v.message_id = p.message_id
+            AND v.value = 1
+    ),
+    (
+        SELECT
+            COUNT(*)
+        FROM
+            votes v
+        WHERE
+            v.channel_id = p.channel_id
+            AND v.message_id = p.message_id
+            AND v.value = 0
+    )
+FROM
+    polls p
+WHERE
+    p.passed = 1
+    AND unixepoch (p.expiry) >= ?
+    AND unixepoch (p.expiry) < ?
+ORDER BY
+    p.points DESC,
+    unixepoch (p.expiry)
+LIMIT
+    1;
\ No newline at end of file
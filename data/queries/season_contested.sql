@@ -0,0 +1,57 @@
+SELECT
+    *
+FROM
+    (
+        SELECT
+            p.channel_id,
+            p.message_id,
+            p.creator_id,
+            p.points,
+            p.reason,
+            p.expiry,
+            p.passed,
+            (
+                SELECT
+                    group_concat (g.user_id, ' ')
+                FROM
+                    gainers g
+                WHERE
+                    g.channel_id = p.channel_id
+                    AND g.message_id = p.message_id
+            ),
+            (
+                SELECT
+                    COUNT(*)
+                FROM
+                    votes v
+                WHERE
+                    v.channel_id = p.channel_id
+                    AND v.message_id = p.message_id
+                    AND v.value = 1
+            ) AS votes_for,
+            (
+                SELECT
+                    COUNT(*)
+                FROM
+                    votes v
+                WHERE
+                    v.channel_id = p.channel_id
+                    AND v.message_id = p.message_id
+                    AND v.value = 0
+            ) AS votes_against
+        FROM
+            polls p
+        WHERE
+            p.passed IS NOT NULL
+            AND unixepoch (p.expiry) >= ?
+            AND unixepoch (p.expiry) < ?
+    )
+WHERE
+    votes_for > 0
+    AND votes_against > 0
+ORDER BY
+    MIN(votes_for, votes_against) DESC,
+    votes_for + votes_against DESC,
+    unixepoch (expiry)
+LIMIT
+    1;
\ No newline at end of file
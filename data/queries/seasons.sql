@@ -0,0 +1,12 @@
+SELECT
+    id,
+    name,
+    starts_at,
+    ends_at,
+    awards,
+    archived_at IS NOT NULL,
+    announced
+FROM
+    seasons
+ORDER BY
+    unixepoch (starts_at) DESC;
\ No newline at end of file
@@ -0,0 +1,11 @@
+SELECT
+    user_id,
+    points,
+    rank
+FROM
+    season_standings
+WHERE
+    season_id = ?
+ORDER BY
+    rank,
+    user_id;
\ No newline at end of file
@@ -0,0 +1,22 @@
+SELECT
+    p.channel_id,
+    p.message_id,
+    p.creator_id,
+    p.points,
+    p.reason,
+    p.expiry,
+    p.passed
+FROM
+    polls p
+    JOIN gainers g ON g.channel_id = p.channel_id
+    AND g.message_id = p.message_id
+WHERE
+    g.user_id = ?
+    AND unixepoch (p.expiry) >= ?
+    AND unixepoch (p.expiry) < ?
+    AND p.passed = 1
+ORDER BY
+    p.points DESC,
+    unixepoch (p.expiry) DESC
+LIMIT
+    1;
\ No newline at end of file
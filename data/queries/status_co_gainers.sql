@@ -0,0 +1,21 @@
+SELECT
+    o.user_id,
+    COUNT(*) AS polls
+FROM
+    gainers g
+    JOIN gainers o ON o.channel_id = g.channel_id
+    AND o.message_id = g.message_id
+    AND o.user_id != g.user_id
+    JOIN polls p ON p.channel_id = g.channel_id
+    AND p.message_id = g.message_id
+WHERE
+    g.user_id = ?
+    AND unixepoch (p.expiry) >= ?
+    AND unixepoch (p.expiry) < ?
+GROUP BY
+    o.user_id
+ORDER BY
+    polls DESC,
+    o.user_id
+LIMIT
+    ?;
\ No newline at end of file
@@ -0,0 +1,16 @@
+SELECT
+    strftime ('%Y-%m', p.expiry, 'localtime') AS month,
+    SUM(p.points)
+FROM
+    polls p
+    JOIN gainers g ON g.channel_id = p.channel_id
+    AND g.message_id = p.message_id
+WHERE
+    g.user_id = ?
+    AND unixepoch (p.expiry) >= ?
+    AND unixepoch (p.expiry) < ?
+    AND p.passed = 1
+GROUP BY
+    month
+ORDER BY
+    month;
\ No newline at end of file
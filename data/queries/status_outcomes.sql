@@ -0,0 +1,12 @@
+SELECT
+    COALESCE(SUM(p.passed = 1), 0),
+    COALESCE(SUM(p.passed = 0), 0),
+    COALESCE(SUM(p.passed IS NULL), 0)
+FROM
+    polls p
+    JOIN gainers g ON g.channel_id = p.channel_id
+    AND g.message_id = p.message_id
+WHERE
+    g.user_id = ?
+    AND unixepoch (p.expiry) >= ?
+    AND unixepoch (p.expiry) < ?;
\ No newline at end of file
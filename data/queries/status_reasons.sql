@@ -0,0 +1,40 @@
+WITH
+    gained AS (
+        SELECT
+            p.reason,
+            p.points,
+            p.passed,
+            lower(trim(p.reason)) AS normalized,
+            ROW_NUMBER() OVER (
+                PARTITION BY
+                    lower(trim(p.reason))
+                ORDER BY
+                    unixepoch (p.expiry),
+                    p.rowid
+            ) AS nth
+        FROM
+            polls p
+            JOIN gainers g ON g.channel_id = p.channel_id
+            AND g.message_id = p.message_id
+        WHERE
+            g.user_id = ?
+            AND unixepoch (p.expiry) >= ?
+            AND unixepoch (p.expiry) < ?
+    )
+SELECT
+    MAX(
+        CASE
+            WHEN nth = 1 THEN reason
+        END
+    ),
+    COUNT(*) AS polls,
+    COALESCE(SUM(points * (passed = 1)), 0)
+FROM
+    gained
+GROUP BY
+    normalized
+ORDER BY
+    polls DESC,
+    normalized
+LIMIT
+    ?;
\ No newline at end of file
@@ -0,0 +1,20 @@
+SELECT
+    p.channel_id,
+    p.message_id,
+    p.creator_id,
+    p.points,
+    p.reason,
+    p.expiry,
+    p.passed
+FROM
+    polls p
+    JOIN gainers g ON g.channel_id = p.channel_id
+    AND g.message_id = p.message_id
+WHERE
+    g.user_id = ?
+    AND unixepoch (p.expiry) >= ?
+    AND unixepoch (p.expiry) < ?
+ORDER BY
+    unixepoch (p.expiry) DESC
+LIMIT
+    ?;
\ No newline at end of file
@@ -0,0 +1,14 @@
+SELECT
+    unixepoch (p.expiry),
+    p.points
+FROM
+    polls p
+    JOIN gainers g ON g.channel_id = p.channel_id
+    AND g.message_id = p.message_id
+WHERE
+    g.user_id = ?
+    AND p.passed = 1
+    AND unixepoch (p.expiry) >= ?
+    AND unixepoch (p.expiry) < ?
+ORDER BY
+    unixepoch (p.expiry);
\ No newline at end of file
@@ -0,0 +1,15 @@
+SELECT
+    id,
+    name,
+    starts_at,
+    ends_at,
+    awards,
+    archived_at IS NOT NULL,
+    announced
+FROM
+    seasons
+WHERE
+    archived_at IS NOT NULL
+    AND announced = 0
+ORDER BY
+    unixepoch (ends_at);
\ No newline at end of file
@@ -0,0 +1,41 @@
+SELECT
+    COUNT(*),
+    COALESCE(SUM(p.creator_id = :voter), 0),
+    COALESCE(
+        SUM(
+            (
+                SELECT
+                    v.value
+                FROM
+                    votes v
+                WHERE
+                    v.channel_id = p.channel_id
+                    AND v.message_id = p.message_id
+                    AND v.user_id = :voter
+            ) = 1
+        ),
+        0
+    ),
+    COALESCE(
+        SUM(
+            (
+                SELECT
+                    v.value
+                FROM
+                    votes v
+                WHERE
+                    v.channel_id = p.channel_id
+                    AND v.message_id = p.message_id
+                    AND v.user_id = :voter
+            ) = 0
+        ),
+        0
+    )
+FROM
+    polls p
+    JOIN gainers g ON g.channel_id = p.channel_id
+    AND g.message_id = p.message_id
+WHERE
+    g.user_id = :gainer
+    AND unixepoch (p.expiry) >= :from
+    AND unixepoch (p.expiry) < :to;
\ No newline at end of file
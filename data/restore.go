@@ -0,0 +1,172 @@
+package data
+
+import (
+	"archive/zip"
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+//go:embed queries/integrity_check.sql
+var integrityCheckQuery string
+
+//go:embed queries/count_tables.sql
+var countTablesQuery string
+
+//go:embed queries/checkpoint.sql
+var checkpointQuery string
+
+// stagedPath is where a staged restore waits for the next Open.
+const stagedPath = dbPath + ".restore"
+
+// Restore replaces the live database with the foulbot.sqlite entry of a zip
+// produced by /logs. The backup is validated before anything is touched, and a
+// snapshot of the current database is written to backupDir first. It returns
+// the path of that safety snapshot. The database is swapped in place, so this
+// is only for the offline restore subcommand; the running bot uses
+// StageRestore.
+func Restore(zipPath string, backupDir string) (string, error) {
+	if err := StageRestore(zipPath); err != nil {
+		return "", err
+	}
+	safety, err := SafetyBackup(backupDir)
+	if err != nil {
+		CancelRestore()
+		return "", err
+	}
+	if err := Close(); err != nil {
+		CancelRestore()
+		return safety, err
+	}
+	Open()
+	return safety, nil
+}
+
+// StageRestore validates the foulbot.sqlite entry of a zip produced by /logs
+// and leaves it for the next Open to put in place, so the database is never
+// swapped while the bot is using it.
+func StageRestore(zipPath string) error {
+	candidate, err := extractBackup(zipPath)
+	if err != nil {
+		return err
+	}
+	if err := validateBackup(candidate); err != nil {
+		os.Remove(candidate)
+		return err
+	}
+	if err := os.Rename(candidate, stagedPath); err != nil {
+		os.Remove(candidate)
+		return err
+	}
+	return nil
+}
+
+// CancelRestore discards a staged restore.
+func CancelRestore() {
+	os.Remove(stagedPath)
+}
+
+// SafetyBackup snapshots the current database into backupDir before a restore
+// and returns the snapshot's path.
+func SafetyBackup(backupDir string) (string, error) {
+	if err := os.MkdirAll(backupDir, 0o755); err != nil {
+		return "", err
+	}
+	safety := filepath.Join(backupDir, "prerestore-"+time.Now().Format("20060102T150405")+backupSuffix)
+	if err := Backup(safety); err != nil {
+		return "", fmt.Errorf("failed to take safety backup: %v", err)
+	}
+	return safety, nil
+}
+
+// Close folds the WAL back into the main file and closes the database.
+func Close() error {
+	if _, err := db.Exec(checkpointQuery); err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+// applyStagedRestore renames a staged restore into place, dropping the WAL
+// files that belong to the database it replaces.
+func applyStagedRestore(path string) {
+	if _, err := os.Stat(stagedPath); err != nil {
+		return
+	}
+	os.Remove(path + "-wal")
+	os.Remove(path + "-shm")
+	if err := os.Rename(stagedPath, path); err != nil {
+		panic(fmt.Errorf("failed to apply staged restore: %v", err))
+	}
+}
+
+// extractBackup copies foulbot.sqlite out of the zip into a temporary file next
+// to the live database, so the final rename stays on one filesystem.
+func extractBackup(zipPath string) (string, error) {
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return "", fmt.Errorf("not a valid zip: %v", err)
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.Name != "foulbot.sqlite" {
+			continue
+		}
+		src, err := f.Open()
+		if err != nil {
+			return "", err
+		}
+		defer src.Close()
+
+		dst, err := os.CreateTemp(filepath.Dir(dbPath), "foulbot-restore-*.sqlite")
+		if err != nil {
+			return "", err
+		}
+		defer dst.Close()
+
+		if _, err := io.Copy(dst, src); err != nil {
+			os.Remove(dst.Name())
+			return "", err
+		}
+		return dst.Name(), nil
+	}
+	return "", fmt.Errorf("zip does not contain foulbot.sqlite")
+}
+
+func validateBackup(path string) error {
+	candidate, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer candidate.Close()
+
+	var result string
+	if err := candidate.QueryRow(integrityCheckQuery).Scan(&result); err != nil {
+		return fmt.Errorf("failed to check integrity: %v", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("integrity check failed: %s", result)
+	}
+
+	var version int
+	if err := candidate.QueryRow(schemaVersionQuery).Scan(&version); err != nil {
+		return err
+	}
+	if version > SchemaVersion {
+		return fmt.Errorf("backup schema version %d is newer than supported version %d", version, SchemaVersion)
+	}
+
+	var tables int
+	if err := candidate.QueryRow(countTablesQuery).Scan(&tables); err != nil {
+		return err
+	}
+	if tables != 3 {
+		return fmt.Errorf("backup is missing foulbot tables")
+	}
+	return nil
+}
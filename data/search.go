@@ -0,0 +1,66 @@
+package data
+
+import (
+	"database/sql"
+	_ "embed"
+	"strings"
+	"time"
+)
+
+//go:embed queries/search.sql
+var searchQuery string
+
+// SearchFilter finds polls whose reason contains every word of Query, or a
+// word starting with it, that expired between From (inclusive) and To
+// (exclusive).
+type SearchFilter struct {
+	Query string    `json:"query"`
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+}
+
+// SearchResult is a matching poll with the matched words of its reason in
+// bold.
+type SearchResult struct {
+	PollSummary
+	Snippet string
+}
+
+// Search returns a page of the polls matching filter, best match first, and
+// how many match in total. The index is kept up to date by triggers on polls.
+func Search(filter SearchFilter, offset, limit int) (results []SearchResult, total int) {
+	query := matchQuery(filter.Query)
+	if query == "" {
+		return nil, 0
+	}
+	rows, err := db.Query(searchQuery,
+		sql.Named("query", query),
+		sql.Named("from", filter.From.Unix()),
+		sql.Named("to", filter.To.Unix()),
+		sql.Named("limit", limit),
+		sql.Named("offset", offset))
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var result SearchResult
+		err = rows.Scan(&result.ChannelId, &result.MessageId, &result.CreatorId, &result.Points, &result.Reason, &result.Expiry, &result.Passed,
+			&result.Snippet, &total)
+		if err != nil {
+			panic(err)
+		}
+		results = append(results, result)
+	}
+	return results, total
+}
+
+// matchQuery quotes each word of text as an FTS5 prefix query, so user input
+// can't be taken for query syntax.
+func matchQuery(text string) string {
+	var terms []string
+	for _, word := range strings.Fields(text) {
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
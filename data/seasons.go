@@ -0,0 +1,322 @@
+package data
+
+import (
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+//go:embed queries/insert_season.sql
+var insertSeasonQuery string
+
+//go:embed queries/season_by_name.sql
+var seasonByNameQuery string
+
+//go:embed queries/unannounced_seasons.sql
+var unannouncedSeasonsQuery string
+
+//go:embed queries/previous_season.sql
+var previousSeasonQuery string
+
+//go:embed queries/archive_season.sql
+var archiveSeasonQuery string
+
+//go:embed queries/insert_standing.sql
+var insertStandingQuery string
+
+//go:embed queries/standings.sql
+var standingsQuery string
+
+//go:embed queries/mark_season_announced.sql
+var markSeasonAnnouncedQuery string
+
+//go:embed queries/pending_polls.sql
+var pendingPollsQuery string
+
+//go:embed queries/season_biggest.sql
+var seasonBiggestQuery string
+
+//go:embed queries/season_contested.sql
+var seasonContestedQuery string
+
+//go:embed queries/current_season.sql
+var currentSeasonQuery string
+
+//go:embed queries/latest_season.sql
+var latestSeasonQuery string
+
+//go:embed queries/seasons.sql
+var seasonsQuery string
+
+//go:embed queries/ended_seasons.sql
+var endedSeasonsQuery string
+
+//go:embed queries/overlapping_seasons.sql
+var overlappingSeasonsQuery string
+
+//go:embed queries/end_season.sql
+var endSeasonQuery string
+
+// OpenEnded is the end of a season that hasn't been ended yet.
+var OpenEnded = time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Season covers polls that expired between From (inclusive) and To
+// (exclusive). Once archived its standings and awards can't change.
+type Season struct {
+	Id        int64
+	Name      string
+	From      time.Time
+	To        time.Time
+	Awards    Awards
+	Archived  bool
+	Announced bool
+}
+
+// Awards are the superlatives of a season besides its standings. Any of them
+// may be missing if nobody qualified.
+type Awards struct {
+	MostPolls *Position    `json:"most_polls,omitempty"` // Points is polls created
+	Biggest   *HistoryPoll `json:"biggest,omitempty"`
+	Contested *HistoryPoll `json:"contested,omitempty"`
+}
+
+// PendingPolls counts polls expiring between from and to that haven't been
+// evaluated yet.
+func PendingPolls(from, to time.Time) (pending int) {
+	err = db.QueryRow(pendingPollsQuery, from.Unix(), to.Unix()).Scan(&pending)
+	if err != nil {
+		panic(err)
+	}
+	return pending
+}
+
+// ArchiveSeason records the final standings and awards of the season called
+// name, creating it with from and to if it doesn't exist. It returns false if
+// the season was already archived.
+func ArchiveSeason(name string, from, to time.Time) bool {
+	_, err = db.Exec(insertSeasonQuery, name, seasonTime(from), seasonTime(to))
+	if err != nil {
+		panic(err)
+	}
+	season, ok := SeasonByName(name)
+	if !ok {
+		panic("season " + name + " vanished while archiving")
+	}
+	if season.Archived {
+		return false
+	}
+
+	// Archive what the season covers, which may have been set before
+	standings := Leaderboard(season.From, season.To)
+	var awards Awards
+	if creators := Board(BoardCreators, season.From, season.To, 0); len(creators) > 0 {
+		awards.MostPolls = &creators[0]
+	}
+	awards.Biggest = superlative(seasonBiggestQuery, season.From, season.To)
+	awards.Contested = superlative(seasonContestedQuery, season.From, season.To)
+	encoded, err := json.Marshal(awards)
+	if err != nil {
+		panic(err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		panic(err)
+	}
+	defer tx.Rollback()
+	result, err := tx.Exec(archiveSeasonQuery, string(encoded), season.Id)
+	if err != nil {
+		panic(err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false // archived by someone else meanwhile
+	}
+	for _, position := range standings {
+		_, err = tx.Exec(insertStandingQuery, season.Id, position.UserId, position.Rank, position.Points)
+		if err != nil {
+			panic(err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		panic(err)
+	}
+	return true
+}
+
+// SeasonByName finds a season, archived or not.
+func SeasonByName(name string) (Season, bool) {
+	return querySeason(db.QueryRow(seasonByNameQuery, name))
+}
+
+// UnannouncedSeasons returns archived seasons whose awards haven't been
+// announced, oldest first.
+func UnannouncedSeasons() []Season {
+	return querySeasons(unannouncedSeasonsQuery)
+}
+
+// StartSeason adds a season, first ending the season with id ending (if not
+// zero) where the new one starts. Both happen in one transaction, so if the
+// name is taken it returns false and nothing changes.
+func StartSeason(name string, from, to time.Time, ending int64) bool {
+	tx, err := db.Begin()
+	if err != nil {
+		panic(err)
+	}
+	defer tx.Rollback()
+
+	if ending != 0 {
+		_, err = tx.Exec(endSeasonQuery, seasonTime(from), ending)
+		if err != nil {
+			panic(err)
+		}
+	}
+	result, err := tx.Exec(insertSeasonQuery, name, seasonTime(from), seasonTime(to))
+	if err != nil {
+		panic(err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false
+	}
+	err = tx.Commit()
+	if err != nil {
+		panic(err)
+	}
+	return true
+}
+
+// EndSeason moves the end of a season that isn't archived to at.
+func EndSeason(id int64, at time.Time) {
+	_, err = db.Exec(endSeasonQuery, seasonTime(at), id)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// CurrentSeason is the season at falls in. If seasons overlap it is the one
+// that started last.
+func CurrentSeason(at time.Time) (Season, bool) {
+	return querySeason(db.QueryRow(currentSeasonQuery, at.Unix(), at.Unix()))
+}
+
+// LatestSeason is the season that started last, whether or not it is over.
+func LatestSeason() (Season, bool) {
+	return querySeason(db.QueryRow(latestSeasonQuery))
+}
+
+// Seasons returns every season, newest first.
+func Seasons() []Season {
+	return querySeasons(seasonsQuery)
+}
+
+// EndedSeasons returns seasons that ended by now but aren't archived, oldest
+// first.
+func EndedSeasons(now time.Time) []Season {
+	return querySeasons(endedSeasonsQuery, now.Unix())
+}
+
+// OverlappingSeasons counts the seasons that cover any of from to to.
+func OverlappingSeasons(from, to time.Time) (count int) {
+	err = db.QueryRow(overlappingSeasonsQuery, to.Unix(), from.Unix()).Scan(&count)
+	if err != nil {
+		panic(err)
+	}
+	return count
+}
+
+// PreviousSeason is the latest archived season that ended before season.
+func PreviousSeason(season Season) (Season, bool) {
+	return querySeason(db.QueryRow(previousSeasonQuery, seasonTime(season.From), season.Id))
+}
+
+func MarkSeasonAnnounced(id int64) {
+	_, err = db.Exec(markSeasonAnnouncedQuery, id)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Standings are the archived final positions of a season.
+func Standings(seasonId int64) (standings []Position) {
+	rows, err := db.Query(standingsQuery, seasonId)
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var position Position
+		err = rows.Scan(&position.UserId, &position.Points, &position.Rank)
+		if err != nil {
+			panic(err)
+		}
+		standings = append(standings, position)
+	}
+	return standings
+}
+
+// seasonTime formats season boundaries like poll expiries.
+func seasonTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// querySeason scans a season from row, which is a *sql.Row or *sql.Rows. It
+// returns false if there was no row.
+func querySeason(row interface{ Scan(...any) error }) (Season, bool) {
+	var season Season
+	var from, to string
+	var awards sql.NullString
+	err := row.Scan(&season.Id, &season.Name, &from, &to, &awards, &season.Archived, &season.Announced)
+	if err == sql.ErrNoRows {
+		return Season{}, false
+	}
+	if err != nil {
+		panic(err)
+	}
+	season.From, err = time.Parse(time.RFC3339, from)
+	if err != nil {
+		panic(err)
+	}
+	season.To, err = time.Parse(time.RFC3339, to)
+	if err != nil {
+		panic(err)
+	}
+	season.From, season.To = season.From.Local(), season.To.Local()
+	if awards.Valid {
+		err = json.Unmarshal([]byte(awards.String), &season.Awards)
+		if err != nil {
+			panic(err)
+		}
+	}
+	return season, true
+}
+
+func querySeasons(query string, args ...any) (seasons []Season) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		season, _ := querySeason(rows)
+		seasons = append(seasons, season)
+	}
+	return seasons
+}
+
+// superlative is the poll query picks for a season, if any.
+func superlative(query string, from, to time.Time) *HistoryPoll {
+	var poll HistoryPoll
+	var gainers sql.NullString
+	err := db.QueryRow(query, from.Unix(), to.Unix()).Scan(&poll.ChannelId, &poll.MessageId, &poll.CreatorId, &poll.Points, &poll.Reason, &poll.Expiry, &poll.Passed,
+		&gainers, &poll.VotesFor, &poll.VotesAgainst)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		panic(err)
+	}
+	poll.GainerIds = strings.Fields(gainers.String)
+	return &poll
+}
@@ -0,0 +1,145 @@
+package data
+
+import (
+	"database/sql"
+	_ "embed"
+	"time"
+)
+
+//go:embed queries/status.sql
+var statusQuery string
+
+//go:embed queries/status_outcomes.sql
+var statusOutcomesQuery string
+
+//go:embed queries/status_biggest.sql
+var statusBiggestQuery string
+
+//go:embed queries/status_recent.sql
+var statusRecentQuery string
+
+//go:embed queries/status_co_gainers.sql
+var statusCoGainersQuery string
+
+//go:embed queries/status_reasons.sql
+var statusReasonsQuery string
+
+//go:embed queries/status_months.sql
+var statusMonthsQuery string
+
+// PollSummary is a poll without its votes. Passed is not valid while the
+// poll is still open.
+type PollSummary struct {
+	ChannelId string
+	MessageId string
+	CreatorId string
+	Points    int64
+	Reason    string
+	Expiry    string
+	Passed    sql.NullBool
+}
+
+type Count struct {
+	Key    string
+	Polls  int
+	Points int64
+}
+
+// StatusReport is everything /status shows about one member for a period.
+// Points only count polls that passed.
+type StatusReport struct {
+	Points int64
+	// Rank on the points leaderboard out of Ranked members, 0 if unranked.
+	Rank   int
+	Ranked int
+
+	Passed  int
+	Failed  int
+	Pending int
+
+	Biggest   *PollSummary
+	Recent    []PollSummary
+	CoGainers []Count // Key is the user id
+	Reasons   []Count // Key is the reason as first written
+	Months    []Count // Key is YYYY-MM, Polls is unused
+}
+
+// Status totals the points userId gained from polls that passed and expired
+// between from (inclusive) and to (exclusive).
+func Status(userId string, from, to time.Time) (points int64) {
+	err = db.QueryRow(statusQuery, userId, from.Unix(), to.Unix()).Scan(&points)
+	if err != nil {
+		panic(err)
+	}
+	return points
+}
+
+// Report collects a member's status for polls that expired between from and
+// to, keeping at most limit rows of each list.
+func Report(userId string, from, to time.Time, limit int) (report StatusReport) {
+	report.Points = Status(userId, from, to)
+
+	leaderboard := Leaderboard(from, to)
+	report.Ranked = len(leaderboard)
+	for _, position := range leaderboard {
+		if position.UserId == userId {
+			report.Rank = position.Rank
+		}
+	}
+
+	err = db.QueryRow(statusOutcomesQuery, userId, from.Unix(), to.Unix()).Scan(&report.Passed, &report.Failed, &report.Pending)
+	if err != nil {
+		panic(err)
+	}
+
+	biggest := pollSummaries(statusBiggestQuery, userId, from.Unix(), to.Unix())
+	if len(biggest) > 0 {
+		report.Biggest = &biggest[0]
+	}
+	report.Recent = pollSummaries(statusRecentQuery, userId, from.Unix(), to.Unix(), limit)
+
+	report.CoGainers = counts(statusCoGainersQuery, func(rows *sql.Rows, count *Count) error {
+		return rows.Scan(&count.Key, &count.Polls)
+	}, userId, from.Unix(), to.Unix(), limit)
+	report.Reasons = counts(statusReasonsQuery, func(rows *sql.Rows, count *Count) error {
+		return rows.Scan(&count.Key, &count.Polls, &count.Points)
+	}, userId, from.Unix(), to.Unix(), limit)
+	report.Months = counts(statusMonthsQuery, func(rows *sql.Rows, count *Count) error {
+		return rows.Scan(&count.Key, &count.Points)
+	}, userId, from.Unix(), to.Unix())
+	return report
+}
+
+func pollSummaries(query string, args ...any) (polls []PollSummary) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var poll PollSummary
+		err = rows.Scan(&poll.ChannelId, &poll.MessageId, &poll.CreatorId, &poll.Points, &poll.Reason, &poll.Expiry, &poll.Passed)
+		if err != nil {
+			panic(err)
+		}
+		polls = append(polls, poll)
+	}
+	return polls
+}
+
+func counts(query string, scan func(*sql.Rows, *Count) error, args ...any) (result []Count) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var count Count
+		err = scan(rows, &count)
+		if err != nil {
+			panic(err)
+		}
+		result = append(result, count)
+	}
+	return result
+}
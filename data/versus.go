@@ -0,0 +1,62 @@
+package data
+
+import (
+	"database/sql"
+	_ "embed"
+	"time"
+)
+
+//go:embed queries/timeline.sql
+var timelineQuery string
+
+//go:embed queries/versus_votes.sql
+var versusVotesQuery string
+
+// Gain is the points a member gained from one poll that passed.
+type Gain struct {
+	Time   time.Time
+	Points int64
+}
+
+// Scrutiny is how one member treated the polls another gained in.
+type Scrutiny struct {
+	Polls   int // polls the other member gained in
+	Created int
+	For     int
+	Against int
+}
+
+// Timeline returns userId's gains from polls that expired between from and
+// to, oldest first.
+func Timeline(userId string, from, to time.Time) (gains []Gain) {
+	rows, err := db.Query(timelineQuery, userId, from.Unix(), to.Unix())
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var expiry int64
+		var gain Gain
+		err = rows.Scan(&expiry, &gain.Points)
+		if err != nil {
+			panic(err)
+		}
+		gain.Time = time.Unix(expiry, 0)
+		gains = append(gains, gain)
+	}
+	return gains
+}
+
+// Scrutinize reports how voterId created and voted on the polls gainerId
+// gained in that expired between from and to.
+func Scrutinize(voterId, gainerId string, from, to time.Time) (scrutiny Scrutiny) {
+	err = db.QueryRow(versusVotesQuery,
+		sql.Named("voter", voterId),
+		sql.Named("gainer", gainerId),
+		sql.Named("from", from.Unix()),
+		sql.Named("to", to.Unix())).Scan(&scrutiny.Polls, &scrutiny.Created, &scrutiny.For, &scrutiny.Against)
+	if err != nil {
+		panic(err)
+	}
+	return scrutiny
+}
@@ -0,0 +1,203 @@
+package discordtest
+
+import (
+	"fmt"
+	"foulbot/discord"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Fake is an in-memory discord.Session that records every call. Messages,
+// threads and followups get sequential ids. Configure Channels, Members and
+// Reactions before use; unknown channels are plain text channels.
+type Fake struct {
+	mu sync.Mutex
+
+	Channels  map[string]*discordgo.Channel
+	Members   map[string]bool
+	Reactions map[string][]*discordgo.User // keyed by message id + emoji
+	Roles     map[string][]string          // role ids by member id
+
+	Responses []*discordgo.InteractionResponse
+	Followups []*discordgo.WebhookParams
+	Messages  []*discordgo.Message
+	Edits     []*discordgo.MessageEdit
+	Threads   []*discordgo.Channel
+
+	// Err, if set, is returned by every call that sends something.
+	Err error
+}
+
+var _ discord.Session = (*Fake)(nil)
+
+func New() *Fake {
+	return &Fake{
+		Channels:  make(map[string]*discordgo.Channel),
+		Members:   make(map[string]bool),
+		Reactions: make(map[string][]*discordgo.User),
+		Roles:     make(map[string][]string),
+	}
+}
+
+// lastId is shared by every Fake so ids stay unique in a shared database.
+var lastId atomic.Int64
+
+func (f *Fake) id() string {
+	return strconv.FormatInt(1000+lastId.Add(1), 10)
+}
+
+func (f *Fake) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	f.Responses = append(f.Responses, resp)
+	return nil
+}
+
+func (f *Fake) FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	f.Followups = append(f.Followups, data)
+	return &discordgo.Message{ID: f.id(), ChannelID: interaction.ChannelID, Content: data.Content, Embeds: data.Embeds}, nil
+}
+
+func (f *Fake) ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return f.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{Content: content}, options...)
+}
+
+func (f *Fake) ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return f.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}, options...)
+}
+
+func (f *Fake) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	message := &discordgo.Message{
+		ID:         f.id(),
+		ChannelID:  channelID,
+		Content:    data.Content,
+		Embeds:     data.Embeds,
+		Components: data.Components,
+	}
+	for _, file := range data.Files {
+		message.Attachments = append(message.Attachments, &discordgo.MessageAttachment{ID: f.id(), Filename: file.Name, ContentType: file.ContentType})
+	}
+	f.Messages = append(f.Messages, message)
+	return message, nil
+}
+
+func (f *Fake) ChannelMessageEditComplex(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	f.Edits = append(f.Edits, m)
+	return &discordgo.Message{ID: m.ID, ChannelID: m.Channel}, nil
+}
+
+// ChannelMessages returns the newest messages sent to channelID, newest
+// first. Only limit is honoured.
+func (f *Fake) ChannelMessages(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var messages []*discordgo.Message
+	for i := len(f.Messages) - 1; i >= 0 && len(messages) < limit; i-- {
+		if f.Messages[i].ChannelID == channelID {
+			messages = append(messages, f.Messages[i])
+		}
+	}
+	return messages, nil
+}
+
+func (f *Fake) MessageThreadStart(channelID, messageID string, name string, archiveDuration int, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	return f.MessageThreadStartComplex(channelID, messageID, &discordgo.ThreadStart{Name: name, AutoArchiveDuration: archiveDuration}, options...)
+}
+
+func (f *Fake) MessageThreadStartComplex(channelID, messageID string, data *discordgo.ThreadStart, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	thread := &discordgo.Channel{
+		ID:       f.id(),
+		ParentID: channelID,
+		Name:     data.Name,
+		Type:     discordgo.ChannelTypeGuildPublicThread,
+	}
+	f.Threads = append(f.Threads, thread)
+	f.Channels[thread.ID] = thread
+	return thread, nil
+}
+
+func (f *Fake) MessageReactions(channelID, messageID, emojiID string, limit int, beforeID, afterID string, options ...discordgo.RequestOption) ([]*discordgo.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.Reactions[messageID+emojiID], nil
+}
+
+func (f *Fake) Channel(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if channel, ok := f.Channels[channelID]; ok {
+		return channel, nil
+	}
+	return &discordgo.Channel{ID: channelID, Type: discordgo.ChannelTypeGuildText}, nil
+}
+
+func (f *Fake) GuildMember(guildID, userID string, options ...discordgo.RequestOption) (*discordgo.Member, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.Members[userID] {
+		return nil, fmt.Errorf("unknown member %s", userID)
+	}
+	return &discordgo.Member{GuildID: guildID, User: &discordgo.User{ID: userID}}, nil
+}
+
+func (f *Fake) GuildMemberRoleAdd(guildID, userID, roleID string, options ...discordgo.RequestOption) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	if !slices.Contains(f.Roles[userID], roleID) {
+		f.Roles[userID] = append(f.Roles[userID], roleID)
+	}
+	return nil
+}
+
+func (f *Fake) GuildMemberRoleRemove(guildID, userID, roleID string, options ...discordgo.RequestOption) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	f.Roles[userID] = slices.DeleteFunc(f.Roles[userID], func(role string) bool { return role == roleID })
+	return nil
+}
+
+func (f *Fake) Open() error  { return nil }
+func (f *Fake) Close() error { return nil }
+
+// LastMessage returns the most recently sent channel message, or nil.
+func (f *Fake) LastMessage() *discordgo.Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.Messages) == 0 {
+		return nil
+	}
+	return f.Messages[len(f.Messages)-1]
+}
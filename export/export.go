@@ -0,0 +1,102 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"foulbot/data"
+	"foulbot/period"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var FORMATS = []string{"csv", "json"}
+
+// Write streams every poll matching year and userId to w in the given format.
+// Years are local, like the leaderboard's; an empty year means all time.
+func Write(w io.Writer, format string, year string, userId string) error {
+	p, err := period.Parse(period.AllTime, "", "", "", time.Now())
+	if year != "" {
+		p, err = period.Parse(period.Year, year, "", "", time.Now())
+	}
+	if err != nil {
+		return fmt.Errorf("invalid year %q: %v", year, err)
+	}
+
+	switch format {
+	case "csv":
+		return writeCSV(w, p, userId)
+	case "json":
+		return writeJSON(w, p, userId)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func writeCSV(w io.Writer, p period.Period, userId string) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"channel_id", "message_id", "creator_id", "points", "reason", "expiry",
+		"passed", "imported", "gainer_ids", "votes_for", "votes_against"})
+	if err != nil {
+		return err
+	}
+
+	err = data.ExportPolls(p.From, p.To, userId, func(poll data.ExportedPoll) error {
+		passed := ""
+		if poll.Passed != nil {
+			passed = strconv.FormatBool(*poll.Passed)
+		}
+		return cw.Write([]string{
+			poll.ChannelId,
+			poll.MessageId,
+			poll.CreatorId,
+			strconv.FormatInt(poll.Points, 10),
+			poll.Reason,
+			poll.Expiry,
+			passed,
+			strconv.FormatBool(poll.Imported),
+			strings.Join(poll.GainerIds, " "),
+			strings.Join(poll.VotesFor, " "),
+			strings.Join(poll.VotesAgainst, " "),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeJSON(w io.Writer, p period.Period, userId string) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	first := true
+	err := data.ExportPolls(p.From, p.To, userId, func(poll data.ExportedPoll) error {
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+
+		// Keep empty lists as [] rather than null
+		for _, ids := range []*[]string{&poll.GainerIds, &poll.VotesFor, &poll.VotesAgainst} {
+			if *ids == nil {
+				*ids = []string{}
+			}
+		}
+		line, err := json.Marshal(poll)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "\n  %s", line)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n]\n")
+	return err
+}
@@ -0,0 +1,82 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"foulbot/data"
+	"slices"
+	"testing"
+	"time"
+)
+
+// setup fills a fresh database with polls around new year in a zone west of
+// UTC, where the local and UTC years disagree.
+func setup(t *testing.T) {
+	t.Helper()
+	local := time.Local
+	time.Local = time.FixedZone("EST", -5*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	data.OpenMemory(t.Name())
+	for _, poll := range []data.Poll{
+		{MessageId: "new-year", Expiry: "2025-01-01T12:00:00-05:00", GainerIds: []string{"dave"}},
+		{MessageId: "late", Expiry: "2024-12-31T20:00:00-05:00", GainerIds: []string{"dave"}},
+		{MessageId: "imported", Expiry: "2025-01-01T02:00:00Z", GainerIds: []string{"erin"}},
+		{MessageId: "early", Expiry: "2025-01-01T00:30:00Z", GainerIds: []string{"erin"}},
+	} {
+		poll.ChannelId, poll.CreatorId, poll.Points, poll.Reason = "channel", "creator", 1, "late"
+		data.CreatePoll(poll)
+	}
+}
+
+func TestWriteCSVUsesLocalYears(t *testing.T) {
+	setup(t)
+
+	var b bytes.Buffer
+	if err := Write(&b, "csv", "2024", ""); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, row := range rows[1:] {
+		ids = append(ids, row[1])
+	}
+	if want := []string{"early", "late", "imported"}; !slices.Equal(ids, want) {
+		t.Errorf("got polls %v, want %v in expiry order", ids, want)
+	}
+}
+
+func TestWriteJSONFiltersByUser(t *testing.T) {
+	setup(t)
+
+	var b bytes.Buffer
+	if err := Write(&b, "json", "", "dave"); err != nil {
+		t.Fatal(err)
+	}
+	var polls []data.ExportedPoll
+	if err := json.Unmarshal(b.Bytes(), &polls); err != nil {
+		t.Fatal(err)
+	}
+	if len(polls) != 2 || polls[0].MessageId != "late" || polls[1].MessageId != "new-year" {
+		t.Fatalf("expected dave's two polls, got %+v", polls)
+	}
+	if polls[0].Passed != nil || polls[0].VotesFor == nil || !slices.Equal(polls[0].GainerIds, []string{"dave"}) {
+		t.Errorf("expected a pending poll with empty vote lists, got %+v", polls[0])
+	}
+}
+
+func TestWriteRejectsInvalidInput(t *testing.T) {
+	setup(t)
+
+	var b bytes.Buffer
+	if err := Write(&b, "csv", "last year", ""); err == nil {
+		t.Error("expected an invalid year to be rejected")
+	}
+	if err := Write(&b, "xml", "", ""); err == nil {
+		t.Error("expected an unknown format to be rejected")
+	}
+}
@@ -7,6 +7,7 @@ toolchain go1.23.4
 require (
 	github.com/bwmarrin/discordgo v0.28.1
 	github.com/inconshreveable/go-update v0.0.0-20160112193335-8152e7eb6ccf
+	golang.org/x/image v0.18.0
 	modernc.org/sqlite v1.34.4
 )
 
@@ -0,0 +1,195 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"foulbot/data"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var DATE_FORMATS = []string{time.RFC3339, "2006-01-02", "2006/01/02"}
+
+var mentionPattern = regexp.MustCompile(`^<@!?(\d+)>$|^(\d+)$`)
+
+type row struct {
+	Date    string          `json:"date"`
+	Gainers json.RawMessage `json:"gainers"`
+	Points  json.Number     `json:"points"`
+	Reason  string          `json:"reason"`
+	Passed  *bool           `json:"passed"`
+}
+
+// FormatFromName picks csv or json from a file name's extension.
+func FormatFromName(name string) string {
+	return strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+}
+
+// Parse reads historical polls from CSV (with a date,gainers,points,reason,passed
+// header) or a JSON array of objects with the same keys. Gainers are user ids
+// or mentions separated by spaces, commas or semicolons; passed defaults to true.
+func Parse(r io.Reader, format string) ([]data.ImportedPoll, error) {
+	var rows []row
+	switch format {
+	case "csv":
+		records, err := csv.NewReader(r).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			return nil, fmt.Errorf("file is empty")
+		}
+		columns := make(map[string]int)
+		for i, name := range records[0] {
+			columns[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+		for _, required := range []string{"date", "gainers", "points", "reason"} {
+			if _, ok := columns[required]; !ok {
+				return nil, fmt.Errorf("missing %q column", required)
+			}
+		}
+		for _, record := range records[1:] {
+			gainers, _ := json.Marshal(record[columns["gainers"]])
+			row := row{
+				Date:    record[columns["date"]],
+				Gainers: gainers,
+				Points:  json.Number(strings.TrimSpace(record[columns["points"]])),
+				Reason:  record[columns["reason"]],
+			}
+			if i, ok := columns["passed"]; ok && strings.TrimSpace(record[i]) != "" {
+				passed, err := strconv.ParseBool(strings.TrimSpace(record[i]))
+				if err != nil {
+					return nil, fmt.Errorf("row %d: invalid passed value %q", len(rows)+1, record[i])
+				}
+				row.Passed = &passed
+			}
+			rows = append(rows, row)
+		}
+	case "json":
+		decoder := json.NewDecoder(r)
+		decoder.UseNumber()
+		if err := decoder.Decode(&rows); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+
+	polls := make([]data.ImportedPoll, 0, len(rows))
+	for n, row := range rows {
+		poll, err := row.poll()
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %v", n+1, err)
+		}
+		polls = append(polls, poll)
+	}
+	return polls, nil
+}
+
+func (r row) poll() (data.ImportedPoll, error) {
+	var poll data.ImportedPoll
+
+	var err error
+	for _, format := range DATE_FORMATS {
+		// Dates without an offset are local, like every other period
+		poll.Date, err = time.ParseInLocation(format, strings.TrimSpace(r.Date), time.Local)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return poll, fmt.Errorf("invalid date %q", r.Date)
+	}
+
+	var gainers []string
+	if err := json.Unmarshal(r.Gainers, &gainers); err != nil {
+		var joined string
+		if err := json.Unmarshal(r.Gainers, &joined); err != nil {
+			return poll, fmt.Errorf("gainers must be a string or a list of strings")
+		}
+		gainers = strings.FieldsFunc(joined, func(c rune) bool {
+			return c == ' ' || c == ',' || c == ';'
+		})
+	}
+	for _, gainer := range gainers {
+		match := mentionPattern.FindStringSubmatch(strings.TrimSpace(gainer))
+		if match == nil {
+			return poll, fmt.Errorf("invalid user id %q", gainer)
+		}
+		poll.GainerIds = append(poll.GainerIds, match[1]+match[2])
+	}
+	if len(poll.GainerIds) == 0 {
+		return poll, fmt.Errorf("no gainers")
+	}
+
+	poll.Points, err = r.Points.Int64()
+	if err != nil || poll.Points == 0 {
+		return poll, fmt.Errorf("invalid points %q", r.Points)
+	}
+
+	poll.Reason = strings.TrimSpace(r.Reason)
+	if poll.Reason == "" {
+		return poll, fmt.Errorf("no reason")
+	}
+
+	poll.Passed = r.Passed == nil || *r.Passed
+	return poll, nil
+}
+
+// UnknownUsers returns the gainer ids for which isMember reports false.
+func UnknownUsers(polls []data.ImportedPoll, isMember func(userId string) bool) []string {
+	checked := make(map[string]bool)
+	var unknown []string
+	for _, poll := range polls {
+		for _, id := range poll.GainerIds {
+			if _, ok := checked[id]; ok {
+				continue
+			}
+			checked[id] = isMember(id)
+			if !checked[id] {
+				unknown = append(unknown, id)
+			}
+		}
+	}
+	return unknown
+}
+
+// Preview summarises what an import would add: passed points per year and
+// the first few rows.
+func Preview(polls []data.ImportedPoll) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d polls\n", len(polls))
+
+	totals := make(map[int]int64)
+	var years []int
+	for _, poll := range polls {
+		year := poll.Date.Local().Year()
+		if _, ok := totals[year]; !ok {
+			years = append(years, year)
+		}
+		if poll.Passed {
+			totals[year] += poll.Points * int64(len(poll.GainerIds))
+		}
+	}
+	sort.Ints(years)
+	for _, year := range years {
+		fmt.Fprintf(&b, "%d: %+d points\n", year, totals[year])
+	}
+
+	for i, poll := range polls {
+		if i == 5 {
+			fmt.Fprintf(&b, "... and %d more\n", len(polls)-i)
+			break
+		}
+		result := map[bool]string{true: "passed", false: "failed"}[poll.Passed]
+		fmt.Fprintf(&b, "%s <@%s> %+d %s (%s)\n", poll.Date.Local().Format("2006-01-02"),
+			strings.Join(poll.GainerIds, "> <@"), poll.Points, poll.Reason, result)
+	}
+	return b.String()
+}
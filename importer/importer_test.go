@@ -0,0 +1,152 @@
+package importer
+
+import (
+	"foulbot/data"
+	"foulbot/period"
+	"strings"
+	"testing"
+	"time"
+)
+
+// inZone runs the test with a local zone west of UTC, where dates read as
+// UTC midnight would land on the previous day.
+func inZone(t *testing.T) {
+	t.Helper()
+	local := time.Local
+	time.Local = time.FixedZone("EST", -5*60*60)
+	t.Cleanup(func() { time.Local = local })
+}
+
+func TestDatesAreLocal(t *testing.T) {
+	inZone(t)
+	data.OpenMemory(t.Name())
+
+	polls, err := Parse(strings.NewReader("date,gainers,points,reason\n2024-01-01,1,3,new year\n2024-12-31,1,5,old year\n"), "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview := Preview(polls); !strings.Contains(preview, "2024: +8 points") || !strings.Contains(preview, "2024-01-01 <@1>") {
+		t.Errorf("expected both rows under 2024, got %q", preview)
+	}
+
+	if _, err := data.ImportPolls("creator", polls); err != nil {
+		t.Fatal(err)
+	}
+	year, _ := period.Parse(period.Year, "2024", "", "", time.Now())
+	if points := data.Status("1", year.From, year.To); points != 8 {
+		t.Errorf("expected both rows to count towards 2024, got %d points", points)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	csv := "Reason,Date,Gainers,Points,Passed\n" +
+		"late,2024-03-01,<@111> <@!222>,2,\n" +
+		"argued,2024/03/02,\"333, 444;555\",-1,false\n" +
+		"offside,2024-03-03T18:30:00Z,111,4,true\n"
+	polls, err := Parse(strings.NewReader(csv), "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polls) != 3 {
+		t.Fatalf("expected 3 polls, got %d", len(polls))
+	}
+	if got := strings.Join(polls[0].GainerIds, " "); got != "111 222" || !polls[0].Passed || polls[0].Reason != "late" {
+		t.Errorf("expected mentions parsed and passed by default, got %+v", polls[0])
+	}
+	if got := strings.Join(polls[1].GainerIds, " "); got != "333 444 555" || polls[1].Passed || polls[1].Points != -1 {
+		t.Errorf("expected ids split on spaces, commas and semicolons, got %+v", polls[1])
+	}
+	if !polls[2].Date.Equal(time.Date(2024, 3, 3, 18, 30, 0, 0, time.UTC)) {
+		t.Errorf("expected the offset to be kept, got %s", polls[2].Date)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	json := `[
+		{"date": "2024-05-01", "gainers": ["<@111>", "222"], "points": 3, "reason": "late"},
+		{"date": "2024-05-02", "gainers": "333,444", "points": 1, "reason": "argued", "passed": false}
+	]`
+	polls, err := Parse(strings.NewReader(json), "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polls) != 2 || strings.Join(polls[0].GainerIds, " ") != "111 222" || !polls[0].Passed {
+		t.Fatalf("expected a list of gainers, passed by default, got %+v", polls)
+	}
+	if strings.Join(polls[1].GainerIds, " ") != "333 444" || polls[1].Passed {
+		t.Errorf("expected a joined string of gainers that failed, got %+v", polls[1])
+	}
+}
+
+func TestParseRejectsInvalidRows(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		input  string
+		want   string
+	}{
+		{"missing column", "csv", "date,gainers,reason\n2024-01-01,1,late\n", `missing "points" column`},
+		{"invalid passed", "csv", "date,gainers,points,reason,passed\n2024-01-01,1,2,late,maybe\n", `row 1: invalid passed value "maybe"`},
+		{"zero points", "csv", "date,gainers,points,reason\n2024-01-01,1,0,late\n", `row 1: invalid points "0"`},
+		{"invalid date", "csv", "date,gainers,points,reason\nyesterday,1,2,late\n", `row 1: invalid date "yesterday"`},
+		{"invalid user", "json", `[{"date": "2024-01-01", "gainers": "dave", "points": 2, "reason": "late"}]`, `row 1: invalid user id "dave"`},
+		{"no reason", "json", `[{"date": "2024-01-01", "gainers": "1", "points": 2, "reason": " "}]`, "row 1: no reason"},
+		{"unknown format", "xml", "", `unknown format "xml"`},
+	}
+	for _, test := range tests {
+		_, err := Parse(strings.NewReader(test.input), test.format)
+		if err == nil || err.Error() != test.want {
+			t.Errorf("%s: got error %v, want %q", test.name, err, test.want)
+		}
+	}
+}
+
+func TestUnknownUsers(t *testing.T) {
+	polls := []data.ImportedPoll{{GainerIds: []string{"1", "2"}}, {GainerIds: []string{"2", "3"}}}
+	checks := 0
+	unknown := UnknownUsers(polls, func(userId string) bool {
+		checks++
+		return userId == "1"
+	})
+	if strings.Join(unknown, " ") != "2 3" || checks != 3 {
+		t.Errorf("expected 2 and 3 to be unknown after checking each user once, got %v after %d checks", unknown, checks)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	var polls []data.ImportedPoll
+	for i := 0; i < 7; i++ {
+		polls = append(polls, data.ImportedPoll{
+			Date:      time.Date(2023+i%2, 6, 1, 12, 0, 0, 0, time.Local),
+			GainerIds: []string{"1", "2"},
+			Points:    1,
+			Reason:    "late",
+			Passed:    i != 0,
+		})
+	}
+	preview := Preview(polls)
+	for _, want := range []string{"7 polls\n", "2023: +6 points\n", "2024: +6 points\n", "2023-06-01 <@1> <@2> +1 late (failed)\n", "... and 2 more\n"} {
+		if !strings.Contains(preview, want) {
+			t.Errorf("expected %q in the preview, got %q", want, preview)
+		}
+	}
+}
+
+func TestReimportIsDeduplicated(t *testing.T) {
+	data.OpenMemory(t.Name())
+	file := "date,gainers,points,reason\n2024-01-01,1,3,late\n2024-01-02,2,1,argued\n"
+
+	for n, want := range []int{2, 0} {
+		polls, err := Parse(strings.NewReader(file), "csv")
+		if err != nil {
+			t.Fatal(err)
+		}
+		inserted, err := data.ImportPolls("creator", polls)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if inserted != want {
+			t.Errorf("import %d inserted %d polls, want %d", n+1, inserted, want)
+		}
+	}
+}
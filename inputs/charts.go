@@ -0,0 +1,56 @@
+package inputs
+
+import (
+	"foulbot/chart"
+	"foulbot/discord"
+	"foulbot/period"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// chartRange is the part of p to plot: up to now, and for all time from the
+// first event.
+func chartRange(p period.Period, events ...[]chart.Point) (time.Time, time.Time) {
+	from, to := p.From, p.To
+	if now := time.Now(); now.Before(to) {
+		to = now
+	}
+	if p.Kind != period.AllTime {
+		return from, to
+	}
+	from = to.AddDate(0, -1, 0)
+	for _, e := range events {
+		if len(e) > 0 && e[0].Time.Before(from) {
+			from = e[0].Time
+		}
+	}
+	return from, to
+}
+
+// keepImage carries a chart attached to message over to its updated embed,
+// since page turns don't redraw it.
+func keepImage(embed *discordgo.MessageEmbed, message *discordgo.Message) {
+	if message != nil && len(message.Embeds) > 0 {
+		embed.Image = message.Embeds[0].Image
+	}
+}
+
+// memberName is the name a member goes by in the guild, for places mentions
+// don't render such as images. It falls back to the user id.
+func memberName(s discord.Session, guildId string, userId string) string {
+	member, err := s.GuildMember(guildId, userId)
+	if err != nil || member.User == nil {
+		return userId
+	}
+	if member.Nick != "" {
+		return member.Nick
+	}
+	if member.User.GlobalName != "" {
+		return member.User.GlobalName
+	}
+	if member.User.Username != "" {
+		return member.User.Username
+	}
+	return userId
+}
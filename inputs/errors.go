@@ -0,0 +1,86 @@
+package inputs
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// recovering turns a panicking handler into a reported error instead of
+// taking the bot down.
+func recovering(next Handler) Handler {
+	return func(req *Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				req.report(fmt.Errorf("panic: %v", r), debug.Stack())
+			}
+		}()
+		next(req)
+	}
+}
+
+// Fail reports an error the user can't do anything about: it is logged under
+// a new error id, the user is told the id, and the details are posted to the
+// error channel if one is configured.
+func (req *Request) Fail(err error) {
+	req.report(err, nil)
+}
+
+func (req *Request) report(err error, stack []byte) {
+	id := errorId()
+	if stack != nil {
+		req.Logger.Error("Handler panicked", "error_id", id, "err", err, "stack", string(stack))
+	} else {
+		req.Logger.Error("Handler failed", "error_id", id, "err", err)
+	}
+
+	i := req.Interaction
+	if i.Type != discordgo.InteractionApplicationCommandAutocomplete {
+		message := fmt.Sprintf("Something went wrong. If it keeps happening, give an admin this error id: `%s`", id)
+		if req.responded {
+			req.Followup(&discordgo.WebhookParams{Content: message, Flags: discordgo.MessageFlagsEphemeral})
+		} else {
+			req.Ephemeral(message)
+		}
+	}
+
+	if req.Config.ErrorChannelID == "" {
+		return
+	}
+	source := "a button"
+	if i.Type != discordgo.InteractionMessageComponent {
+		source = "/" + i.ApplicationCommandData().Name
+	} else if req.Command != nil {
+		source = "a /" + req.Command.Definition().Name + " button"
+	}
+	user := "someone"
+	if i.Member != nil && i.Member.User != nil {
+		user = fmt.Sprintf("<@%s>", i.Member.User.ID)
+	}
+	message := &discordgo.MessageSend{
+		Content:         truncateString(fmt.Sprintf("Error `%s` in %s run by %s in <#%s>: %s", id, source, user, i.ChannelID, err), 2000),
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	}
+	if stack != nil {
+		message.Files = []*discordgo.File{{
+			Name:        "stack-" + id + ".txt",
+			ContentType: "text/plain",
+			Reader:      bytes.NewReader(stack),
+		}}
+	}
+	_, err = req.Session.ChannelMessageSendComplex(req.Config.ErrorChannelID, message)
+	if err != nil {
+		req.Logger.Error("Failed to post to the error channel", "error_id", id, "err", err)
+	}
+}
+
+// errorId is a short random id that ties what the user sees to the logs.
+func errorId() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,110 @@
+package inputs
+
+import (
+	"fmt"
+	"foulbot/export"
+	"io"
+	"os"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// exportCommand uploads the poll history as CSV or JSON.
+type exportCommand struct{}
+
+func (exportCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:                     "export",
+		Description:              "Exports poll history as a CSV or JSON file",
+		DefaultMemberPermissions: &adminPermissions,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "format",
+				Description: "File format (defaults to csv)",
+				Required:    false,
+				Choices: func() []*discordgo.ApplicationCommandOptionChoice {
+					choices := make([]*discordgo.ApplicationCommandOptionChoice, len(export.FORMATS))
+					for i, format := range export.FORMATS {
+						choices[i] = &discordgo.ApplicationCommandOptionChoice{Name: format, Value: format}
+					}
+					return choices
+				}(),
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "year",
+				Description: "Only include polls from this year",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user",
+				Description: "Only include polls this user created or gained in",
+				Required:    false,
+			},
+		},
+	}
+}
+
+func (exportCommand) Handle(req *Request) {
+	s, i, logger := req.Session, req.Interaction, req.Logger
+	options := i.ApplicationCommandData().Options
+
+	format, year, userId := "csv", "", ""
+	for _, option := range options {
+		switch option.Name {
+		case "format":
+			format = option.StringValue()
+		case "year":
+			year = option.StringValue()
+		case "user":
+			userId = option.UserValue(nil).ID
+		}
+	}
+
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	// Stream to disk first so large histories never sit in memory
+	exportFile, err := os.CreateTemp("", "foulbot-export-*."+format)
+	if err != nil {
+		req.Followup(&discordgo.WebhookParams{
+			Content: fmt.Sprintf("Failed to create export file: %s", err),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return
+	}
+	defer os.Remove(exportFile.Name())
+	defer exportFile.Close()
+
+	err = export.Write(exportFile, format, year, userId)
+	if err == nil {
+		_, err = exportFile.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		req.Followup(&discordgo.WebhookParams{
+			Content: fmt.Sprintf("Failed to export polls: %s", err),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return
+	}
+
+	_, err = s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
+		Content: "Here is the poll history:",
+		Flags:   discordgo.MessageFlagsEphemeral,
+		Files: []*discordgo.File{
+			{
+				Name:   "foulbot-polls." + format,
+				Reader: exportFile,
+			},
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to upload export", "err", err)
+	}
+}
@@ -0,0 +1,222 @@
+package inputs
+
+import (
+	"fmt"
+	"foulbot/config"
+	"foulbot/data"
+	"foulbot/period"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// historyCommand browses past polls. Its filters are saved so the page
+// buttons only need to carry the search id.
+type historyCommand struct{}
+
+// historySearch is what /history saves for its page buttons.
+type historySearch struct {
+	Filter data.HistoryFilter `json:"filter"`
+	Period string             `json:"period"`
+}
+
+func (historyCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        "history",
+		Description: "Browse past polls",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user",
+				Description: "Only polls this user gained in",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "creator",
+				Description: "Only polls this user created",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "outcome",
+				Description: "Only polls with this outcome",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: data.OutcomePassed, Value: data.OutcomePassed},
+					{Name: data.OutcomeFailed, Value: data.OutcomeFailed},
+					{Name: data.OutcomePending, Value: data.OutcomePending},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "text",
+				Description: "Only polls whose reason contains this",
+				Required:    false,
+			},
+			periodOption("Period to browse (defaults to all time)"),
+			whenOption,
+			fromOption,
+			toOption,
+		},
+	}
+}
+
+func (historyCommand) Handle(req *Request) {
+	options := req.Interaction.ApplicationCommandData().Options
+
+	p, err := parsePeriod(options)
+	if err != nil {
+		req.Ephemeral(fmt.Sprintf("Invalid period: %s", err))
+		return
+	}
+	if !hasPeriod(options) {
+		p, _ = period.Parse(period.AllTime, "", "", "", time.Now())
+	}
+	search := historySearch{Filter: data.HistoryFilter{From: p.From, To: p.To}, Period: p.Name}
+	filter := &search.Filter
+	for _, option := range options {
+		switch option.Name {
+		case "user":
+			filter.GainerId = option.UserValue(nil).ID
+		case "creator":
+			filter.CreatorId = option.UserValue(nil).ID
+		case "outcome":
+			filter.Outcome = option.StringValue()
+		case "text":
+			filter.Text = option.StringValue()
+		}
+	}
+
+	id := data.SaveSearch("history", search)
+	embed, components := historyPage(id, search, 0, req.Interaction.GuildID)
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// Autocomplete suggests seasons for the when option.
+func (historyCommand) Autocomplete(req *Request) {
+	autocompletePeriod(req)
+}
+
+func (historyCommand) Components() []string {
+	return []string{"history_page"}
+}
+
+// HandleComponent turns the page. The state is the saved search id.
+func (historyCommand) HandleComponent(req *Request) {
+	_, page, state, err := parsePageId(req.Interaction.MessageComponentData().CustomID)
+	if err != nil {
+		req.Fail(err)
+		return
+	}
+	id, err := strconv.ParseInt(state, 10, 64)
+	if err != nil {
+		req.Fail(fmt.Errorf("invalid search id %q", state))
+		return
+	}
+	var search historySearch
+	if !data.LoadSearch(id, "history", &search) {
+		req.Ephemeral("This search has expired, run /history again.")
+		return
+	}
+
+	embed, components := historyPage(id, search, page, req.Interaction.GuildID)
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+}
+
+func historyPage(id int64, search historySearch, page int, guildId string) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	filter := search.Filter
+	page = max(page, 0)
+	polls, total := data.History(filter, page*config.PAGE_SIZE, config.PAGE_SIZE)
+	count := pages(total, config.PAGE_SIZE)
+	if page >= count {
+		// Polls were deleted since the buttons were made
+		page = count - 1
+		polls, total = data.History(filter, page*config.PAGE_SIZE, config.PAGE_SIZE)
+	}
+
+	var b strings.Builder
+	if description := describeFilter(filter); description != "" {
+		b.WriteString(description + "\n\n")
+	}
+	for _, poll := range polls {
+		fmt.Fprintf(&b, "%s\n", formatHistoryPoll(guildId, poll))
+	}
+	if total == 0 {
+		b.WriteString("No polls found.")
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "History, " + search.Period,
+		Description: truncateString(b.String(), 4096),
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("%s, page %d of %d", plural(int64(total), "poll"), page+1, count)},
+	}
+	if count == 1 {
+		return embed, nil
+	}
+	buttons := pageButtons("history_page", page, count, strconv.FormatInt(id, 10))
+	return embed, []discordgo.MessageComponent{discordgo.ActionsRow{Components: buttons}}
+}
+
+func formatHistoryPoll(guildId string, poll data.HistoryPoll) string {
+	gainers := make([]string, len(poll.GainerIds))
+	for i, id := range poll.GainerIds {
+		gainers[i] = fmt.Sprintf("<@%s>", id)
+	}
+	return fmt.Sprintf("`%s` **%+d** %s %s, %s 👍 %d 👎 %d, by <@%s>", pollDate(poll.PollSummary), poll.Points, strings.Join(gainers, " "),
+		pollLink(guildId, poll.PollSummary), outcome(poll.PollSummary), poll.VotesFor, poll.VotesAgainst, poll.CreatorId)
+}
+
+// pollDate is the local day a poll expired.
+func pollDate(poll data.PollSummary) string {
+	expiry, err := time.Parse(time.RFC3339, poll.Expiry)
+	if err != nil {
+		return poll.Expiry
+	}
+	return expiry.Local().Format("2006-01-02")
+}
+
+func outcome(poll data.PollSummary) string {
+	if !poll.Passed.Valid {
+		return data.OutcomePending
+	}
+	if poll.Passed.Bool {
+		return data.OutcomePassed
+	}
+	return data.OutcomeFailed
+}
+
+func describeFilter(filter data.HistoryFilter) string {
+	var parts []string
+	if filter.GainerId != "" {
+		parts = append(parts, fmt.Sprintf("gained by <@%s>", filter.GainerId))
+	}
+	if filter.CreatorId != "" {
+		parts = append(parts, fmt.Sprintf("created by <@%s>", filter.CreatorId))
+	}
+	if filter.Outcome != "" {
+		parts = append(parts, filter.Outcome)
+	}
+	if filter.Text != "" {
+		parts = append(parts, fmt.Sprintf("mentioning %q", filter.Text))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "Polls " + strings.Join(parts, ", ")
+}
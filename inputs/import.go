@@ -0,0 +1,114 @@
+package inputs
+
+import (
+	"fmt"
+	"foulbot/data"
+	"foulbot/importer"
+	"os"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// importCommand adds historical polls from an uploaded CSV or JSON file.
+type importCommand struct{}
+
+func (importCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:                     "import",
+		Description:              "Adds historical polls from a CSV or JSON file",
+		DefaultMemberPermissions: &adminPermissions,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionAttachment,
+				Name:        "file",
+				Description: "CSV or JSON with date, gainers, points, reason and passed",
+				Required:    true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "dry_run",
+				Description: "Only preview what would be imported",
+				Required:    false,
+			},
+		},
+	}
+}
+
+func (importCommand) Handle(req *Request) {
+	s, i := req.Session, req.Interaction
+	options := i.ApplicationCommandData().Options
+
+	dryRun := len(options) > 1 && options[1].BoolValue()
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	attachment := i.ApplicationCommandData().Resolved.Attachments[options[0].Value.(string)]
+	path, err := downloadAttachment(attachment.URL)
+	if err != nil {
+		req.Followup(&discordgo.WebhookParams{
+			Content: fmt.Sprintf("Failed to download file: %s", err),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return
+	}
+	defer os.Remove(path)
+
+	f, err := os.Open(path)
+	if err != nil {
+		req.Followup(&discordgo.WebhookParams{
+			Content: fmt.Sprintf("Failed to read file: %s", err),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return
+	}
+	defer f.Close()
+
+	polls, err := importer.Parse(f, importer.FormatFromName(attachment.Filename))
+	if err != nil {
+		req.Followup(&discordgo.WebhookParams{
+			Content: fmt.Sprintf("Import failed: %s", err),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return
+	}
+
+	unknown := importer.UnknownUsers(polls, func(userId string) bool {
+		_, err := s.GuildMember(i.GuildID, userId)
+		return err == nil
+	})
+	if len(unknown) > 0 {
+		req.Followup(&discordgo.WebhookParams{
+			Content: fmt.Sprintf("Import failed, not guild members: <@%s>", strings.Join(unknown, "> <@")),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return
+	}
+
+	preview := importer.Preview(polls)
+	if dryRun {
+		req.Followup(&discordgo.WebhookParams{
+			Content: truncateString("Dry run, nothing was imported:\n"+preview, 2000),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return
+	}
+
+	inserted, err := data.ImportPolls(i.Member.User.ID, polls)
+	if err != nil {
+		req.Followup(&discordgo.WebhookParams{
+			Content: fmt.Sprintf("Import failed: %s", err),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return
+	}
+	req.Followup(&discordgo.WebhookParams{
+		Content: truncateString(fmt.Sprintf("Imported %d new polls (%d already present):\n%s",
+			inserted, len(polls)-inserted, preview), 2000),
+		Flags: discordgo.MessageFlagsEphemeral,
+	})
+}
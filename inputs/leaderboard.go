@@ -0,0 +1,275 @@
+package inputs
+
+import (
+	"bytes"
+	"fmt"
+	"foulbot/chart"
+	"foulbot/config"
+	"foulbot/data"
+	"foulbot/discord"
+	"foulbot/period"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// leaderboardCommand posts the points leaderboard for a period.
+type leaderboardCommand struct{}
+
+func (leaderboardCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        "leaderboard",
+		Description: "Displays the points leaderboard",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "type",
+				Description: "What to rank members by (defaults to points)",
+				Required:    false,
+				Choices: func() []*discordgo.ApplicationCommandOptionChoice {
+					choices := make([]*discordgo.ApplicationCommandOptionChoice, len(data.BOARDS))
+					for i, board := range data.BOARDS {
+						choices[i] = &discordgo.ApplicationCommandOptionChoice{Name: board, Value: board}
+					}
+					return choices
+				}(),
+			},
+			periodOption("Period to show the leaderboard for (defaults to the current season or this year)"),
+			whenOption,
+			fromOption,
+			toOption,
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "chart",
+				Description: "Attach a chart of the leaderboard",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "bars, the top of the leaderboard", Value: "bars"},
+					{Name: "race, points over time of the leaders", Value: "race"},
+				},
+			},
+		},
+	}
+}
+
+func (leaderboardCommand) Handle(req *Request) {
+	s, i, logger := req.Session, req.Interaction, req.Logger
+
+	options := i.ApplicationCommandData().Options
+	p, err := parsePeriod(options)
+	if err != nil {
+		req.Ephemeral(fmt.Sprintf("Invalid period: %s", err))
+		return
+	}
+	board, chartKind := data.BoardPoints, ""
+	for _, option := range options {
+		switch option.Name {
+		case "type":
+			board = option.StringValue()
+		case "chart":
+			chartKind = option.StringValue()
+		}
+	}
+	leaderboard := data.Board(board, p.From, p.To, config.RATE_MIN_SAMPLE)
+	req.Ephemeral(yourRank(leaderboard, board, p, i.Member.User.ID))
+
+	embed, components := create_leaderboard(leaderboard, board, p, 0, i.Member.User.ID)
+	var files []*discordgo.File
+	if chartKind != "" {
+		file, err := leaderboardChart(s, i.GuildID, chartKind, leaderboard, board, p)
+		if err != nil {
+			req.Fail(fmt.Errorf("failed to draw leaderboard chart: %v", err))
+			return
+		}
+		embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://" + file.Name}
+		files = append(files, file)
+	}
+	msg, err := s.ChannelMessageSendComplex(i.ChannelID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: components,
+		Files:      files,
+	})
+	if err != nil {
+		req.Fail(fmt.Errorf("failed to send leaderboard: %v", err))
+		return
+	}
+	_, err = s.MessageThreadStart(i.ChannelID, msg.ID, "Leaderboard", 60)
+	if err != nil {
+		logger.Error("Failed to start leaderboard thread", "err", err)
+	}
+}
+
+// Autocomplete suggests seasons for the when option.
+func (leaderboardCommand) Autocomplete(req *Request) {
+	autocompletePeriod(req)
+}
+
+func (leaderboardCommand) Components() []string {
+	return []string{"leaderboard_page", "leaderboard_me"}
+}
+
+// HandleComponent turns the page, or jumps to the page of whoever clicked.
+// The state is the requesting user, the board and the period key.
+func (leaderboardCommand) HandleComponent(req *Request) {
+	i := req.Interaction
+	prefix, page, state, err := parsePageId(i.MessageComponentData().CustomID)
+	if err != nil {
+		req.Fail(err)
+		return
+	}
+	parts := strings.SplitN(state, ":", 3)
+	if len(parts) != 3 {
+		req.Fail(fmt.Errorf("invalid leaderboard state %q", state))
+		return
+	}
+	userId, board := parts[0], parts[1]
+	p, err := periodFromKey(parts[2])
+	if err != nil {
+		req.Fail(err)
+		return
+	}
+
+	leaderboard := data.Board(board, p.From, p.To, config.RATE_MIN_SAMPLE)
+	if prefix == "leaderboard_me" {
+		index := slices.IndexFunc(leaderboard, func(position data.Position) bool {
+			return position.UserId == i.Member.User.ID
+		})
+		if index < 0 {
+			req.Ephemeral(yourRank(leaderboard, board, p, i.Member.User.ID))
+			return
+		}
+		page = index / config.PAGE_SIZE
+	}
+
+	embed, components := create_leaderboard(leaderboard, board, p, page, userId)
+	keepImage(embed, i.Message)
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+}
+
+// create_leaderboard renders one page of leaderboard with its navigation
+// buttons.
+func create_leaderboard(leaderboard []data.Position, board string, p period.Period, page int, userId string) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	total := pages(len(leaderboard), config.PAGE_SIZE)
+	page = clampPage(page, total)
+
+	description := ""
+	for _, position := range leaderboard[min(page*config.PAGE_SIZE, len(leaderboard)):min((page+1)*config.PAGE_SIZE, len(leaderboard))] {
+		description += fmt.Sprintf("%s <@%s>: %s\n", rankLabel(position.Rank), position.UserId, formatPosition(board, position))
+	}
+	if len(leaderboard) == 0 {
+		description = "Nobody is on this leaderboard yet.\n"
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("%s %s", BOARD_TITLES[board], p.Name),
+		Description: description + fmt.Sprintf("\nMade by <@%s>", userId),
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Page %d of %d", page+1, total)},
+	}
+
+	state := userId + ":" + board + ":" + p.Key()
+	buttons := append(pageButtons("leaderboard_page", page, total, state), discordgo.Button{
+		Style:    discordgo.PrimaryButton,
+		Label:    "Jump to me",
+		CustomID: pageId("leaderboard_me", 0, state),
+	})
+	return embed, []discordgo.MessageComponent{discordgo.ActionsRow{Components: buttons}}
+}
+
+// leaderboardChart draws the top of the leaderboard as bars, or the points
+// race between the leaders.
+func leaderboardChart(s discord.Session, guildId string, kind string, leaderboard []data.Position, board string, p period.Period) (*discordgo.File, error) {
+	var image bytes.Buffer
+	if kind == "race" {
+		leaders := data.Board(data.BoardPoints, p.From, p.To, config.RATE_MIN_SAMPLE)
+		events := make([][]chart.Point, min(len(leaders), config.RACE_SIZE))
+		for n := range events {
+			for _, gain := range data.Timeline(leaders[n].UserId, p.From, p.To) {
+				events[n] = append(events[n], chart.Point{Time: gain.Time, Value: float64(gain.Points)})
+			}
+		}
+		from, to := chartRange(p, events...)
+		series := make([]chart.Series, len(events))
+		for n := range events {
+			series[n] = chart.Cumulative(memberName(s, guildId, leaders[n].UserId), from, to, events[n])
+		}
+		err := chart.Line(&image, "Points race "+p.Name, from, to, series)
+		return &discordgo.File{Name: "race.png", ContentType: "image/png", Reader: &image}, err
+	}
+
+	bars := make([]chart.Bar, 0, config.PAGE_SIZE)
+	for _, position := range leaderboard[:min(len(leaderboard), config.PAGE_SIZE)] {
+		bar := chart.Bar{Label: memberName(s, guildId, position.UserId), Value: float64(position.Points)}
+		if data.IsRate(board) {
+			bar.Value = float64(position.Points * 100 / position.Of)
+			bar.Text = fmt.Sprintf("%d%%", position.Points*100/position.Of)
+		}
+		bars = append(bars, bar)
+	}
+	err := chart.Bars(&image, fmt.Sprintf("%s %s", BOARD_TITLES[board], p.Name), bars)
+	return &discordgo.File{Name: "leaderboard.png", ContentType: "image/png", Reader: &image}, err
+}
+
+// rankLabel is the emoji number for the top ranks and #n after that.
+func rankLabel(rank int) string {
+	if rank >= 1 && rank <= len(config.NUMBERS) {
+		return config.NUMBERS[rank-1]
+	}
+	return fmt.Sprintf("**#%d**", rank)
+}
+
+// BOARD_TITLES name each of data.BOARDS in embed titles.
+var BOARD_TITLES = map[string]string{
+	data.BoardPoints:     "Leaderboard",
+	data.BoardCreators:   "Most polls created",
+	data.BoardPassRate:   "Best poll pass rate",
+	data.BoardVotes:      "Most votes cast",
+	data.BoardContrarian: "Most contrarian voters",
+	data.BoardLenient:    "Most lenient voters",
+}
+
+// formatPosition describes what a position is ranked by on board.
+func formatPosition(board string, position data.Position) string {
+	switch board {
+	case data.BoardCreators:
+		return plural(position.Points, "poll")
+	case data.BoardPassRate:
+		return fmt.Sprintf("%d%% (%d of %d passed)", position.Points*100/position.Of, position.Points, position.Of)
+	case data.BoardVotes:
+		return plural(position.Points, "vote")
+	case data.BoardContrarian:
+		return fmt.Sprintf("%d%% (%d of %d votes against the outcome)", position.Points*100/position.Of, position.Points, position.Of)
+	case data.BoardLenient:
+		return fmt.Sprintf("%d%% (%d of %d votes yes)", position.Points*100/position.Of, position.Points, position.Of)
+	}
+	return strconv.FormatInt(position.Points, 10)
+}
+
+func yourRank(leaderboard []data.Position, board string, p period.Period, userId string) string {
+	for _, position := range leaderboard {
+		if position.UserId == userId {
+			value := formatPosition(board, position)
+			if board == data.BoardPoints {
+				value += " points"
+			}
+			return fmt.Sprintf("Your rank is #%d of %d for %s, with %s.", position.Rank, len(leaderboard), p.Name, value)
+		}
+	}
+	if board == data.BoardPoints {
+		return fmt.Sprintf("You have no points for %s.", p.Name)
+	}
+	return fmt.Sprintf("You are not on this leaderboard for %s.", p.Name)
+}
+
+func plural(n int64, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
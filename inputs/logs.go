@@ -0,0 +1,102 @@
+package inputs
+
+import (
+	"archive/zip"
+	"fmt"
+	"foulbot/config"
+	"foulbot/data"
+	"foulbot/logging"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// logsCommand uploads a database snapshot and the recent logs.
+type logsCommand struct{}
+
+func (logsCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:                     "logs",
+		Description:              "Uploads files importing for debugging",
+		DefaultMemberPermissions: &adminPermissions,
+		Options:                  []*discordgo.ApplicationCommandOption{},
+	}
+}
+
+func (logsCommand) Handle(req *Request) {
+	s, i, logger := req.Session, req.Interaction, req.Logger
+
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	// Create a temporary zip file
+	zipFile, err := os.CreateTemp("", "foulbot-db-*.zip")
+	if err != nil {
+		req.Followup(&discordgo.WebhookParams{
+			Content: fmt.Sprintf("Failed to create temp zip: %s", err),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return
+	}
+	defer os.Remove(zipFile.Name())
+	defer zipFile.Close()
+
+	// Add a consistent snapshot of the database and the recent logs
+	zipWriter := zip.NewWriter(zipFile)
+	err = data.AddBackupToZip(zipWriter)
+	if err == nil {
+		err = addLogsToZip(zipWriter)
+	}
+	if err == nil {
+		err = zipWriter.Close()
+	}
+	if err == nil {
+		_, err = zipFile.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		req.Followup(&discordgo.WebhookParams{
+			Content: fmt.Sprintf("Failed to back up database: %s", err),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return
+	}
+
+	_, err = s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
+		Content: "Here is the database and recent logs:",
+		Flags:   discordgo.MessageFlagsEphemeral,
+		Files: []*discordgo.File{
+			{
+				Name:   "foulbot-db.zip",
+				Reader: zipFile,
+			},
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to upload database zip", "err", err)
+	}
+}
+
+// addLogsToZip stores the most recent log files under logs/ in zw.
+func addLogsToZip(zw *zip.Writer) error {
+	for _, path := range logging.Files(config.LOG_FILES_IN_ZIP) {
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		dst, err := zw.Create("logs/" + filepath.Base(path))
+		if err == nil {
+			_, err = io.Copy(dst, src)
+		}
+		src.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package inputs
+
+import (
+	"foulbot/lifecycle"
+	"foulbot/metrics"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// tracking refuses new work once shutdown has started and lets the drain wait
+// for handlers that are already running.
+func tracking(tracker *lifecycle.Tracker) Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) {
+			if req.Ctx.Err() != nil || !tracker.Start() {
+				// Autocomplete cannot be answered with a message
+				if req.Interaction.Type != discordgo.InteractionApplicationCommandAutocomplete {
+					respondShuttingDown(req.Logger, req.Session, req.Interaction)
+				}
+				return
+			}
+			defer tracker.Done()
+			next(req)
+		}
+	}
+}
+
+func logged(next Handler) Handler {
+	return func(req *Request) {
+		start := time.Now()
+		next(req)
+		req.Logger.Debug("Handled interaction", "duration", time.Since(start))
+	}
+}
+
+func counted(next Handler) Handler {
+	return func(req *Request) {
+		if req.Interaction.Type == discordgo.InteractionApplicationCommand {
+			metrics.Commands.Inc(req.Command.Definition().Name)
+		}
+		next(req)
+	}
+}
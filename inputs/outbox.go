@@ -0,0 +1,101 @@
+package inputs
+
+import (
+	"fmt"
+	"foulbot/data"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// outboxCommand lists and retries results that could not be posted.
+type outboxCommand struct{}
+
+func (outboxCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:                     "outbox",
+		Description:              "Inspect poll results that could not be posted",
+		DefaultMemberPermissions: &adminPermissions,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "dead",
+				Description: "List results that ran out of retries",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "retry",
+				Description: "Try a dead result again",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionInteger,
+						Name:         "id",
+						Description:  "The entry id from /outbox dead",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (outboxCommand) Handle(req *Request) {
+	options := req.Interaction.ApplicationCommandData().Options
+
+	subcommand := options[0]
+	var content string
+	switch subcommand.Name {
+	case "dead":
+		content = formatDeadLetters(data.DeadOutbox())
+	case "retry":
+		id := subcommand.Options[0].IntValue()
+		if data.RetryOutbox(id) {
+			content = fmt.Sprintf("Entry %d will be retried within a minute.", id)
+		} else {
+			content = fmt.Sprintf("Entry %d is not a dead letter.", id)
+		}
+	}
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// Autocomplete suggests the dead letters that can be retried.
+func (outboxCommand) Autocomplete(req *Request) {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, entry := range data.DeadOutbox() {
+		if len(choices) == 25 {
+			break
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  truncateString(fmt.Sprintf("%d: %s for poll %s", entry.Id, entry.Kind, entry.Payload.MessageId), 100),
+			Value: entry.Id,
+		})
+	}
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+}
+
+func formatDeadLetters(entries []data.OutboxEntry) string {
+	if len(entries) == 0 {
+		return "No dead letters."
+	}
+	var b strings.Builder
+	for _, entry := range entries {
+		line := fmt.Sprintf("`%d` %s for poll %s in <#%s> after %d attempts: %s\n",
+			entry.Id, entry.Kind, entry.Payload.MessageId, entry.Payload.ChannelId, entry.Attempts, entry.LastError)
+		if b.Len()+len(line) > 1900 {
+			fmt.Fprintf(&b, "...")
+			break
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
@@ -0,0 +1,283 @@
+package inputs
+
+import (
+	"fmt"
+	"foulbot/config"
+	"foulbot/data"
+	"foulbot/discord"
+	"foulbot/metrics"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ownCommand opens a poll on whether users gained points; its buttons record votes.
+type ownCommand struct{}
+
+func (ownCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        "own",
+		Description: "Accuse someone of gaining",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user",
+				Description: "The user to mention",
+				Required:    true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "number",
+				Description: "An integer value",
+				Required:    true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "reason",
+				Description: "The reason for gaining",
+				Required:    true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user2",
+				Description: "Additional user to mention (optional)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user3",
+				Description: "Additional user to mention (optional)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user4",
+				Description: "Additional user to mention (optional)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user5",
+				Description: "Additional user to mention (optional)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user6",
+				Description: "Additional user to mention (optional)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user7",
+				Description: "Additional user to mention (optional)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user8",
+				Description: "Additional user to mention (optional)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user9",
+				Description: "Additional user to mention (optional)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user10",
+				Description: "Additional user to mention (optional)",
+				Required:    false,
+			},
+		},
+	}
+}
+
+func (ownCommand) Handle(req *Request) {
+	s, i, logger := req.Session, req.Interaction, req.Logger
+	options := i.ApplicationCommandData().Options
+
+	ch, err := s.Channel(i.ChannelID)
+	if err == nil {
+		if ch.Type == discordgo.ChannelTypeGuildPublicThread ||
+			ch.Type == discordgo.ChannelTypeGuildPrivateThread ||
+			ch.Type == discordgo.ChannelTypeGuildNewsThread {
+			req.Respond(&discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "Polls cannot be created in threads. Please use a regular channel.",
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+	} else {
+		logger.Warn("Failed to get channel data", "err", err)
+	}
+
+	user := options[0].UserValue(nil)
+	number := options[1].IntValue()
+	reason := options[2].StringValue()
+
+	// create a list of users
+	var users []*discordgo.User
+	if user != nil {
+		users = append(users, user)
+	}
+	for _, option := range options[3:] {
+		if option.Type == discordgo.ApplicationCommandOptionUser {
+			if userValue := option.UserValue(nil); userValue != nil {
+				users = append(users, userValue)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	unique := make([]*discordgo.User, 0, len(users))
+	for _, user := range users {
+		if !seen[user.ID] {
+			seen[user.ID] = true
+			unique = append(unique, user)
+		}
+	}
+	users = unique
+
+	if number == 0 {
+		req.Respond(&discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Can't give out 0 points",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: "Creating poll...",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	expiry := time.Now().Add(config.POLL_LENGTH).Format(time.RFC3339)
+
+	pollMsg, err := s.ChannelMessageSendComplex(i.ChannelID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title: "Own",
+				Fields: []*discordgo.MessageEmbedField{
+					{
+						Name:   "Gainers",
+						Value:  formatUserMentions(users),
+						Inline: true,
+					},
+					{
+						Name:   "Points",
+						Value:  fmt.Sprintf("%+d", number),
+						Inline: true,
+					},
+					{
+						Name:   "Reason",
+						Value:  reason,
+						Inline: false,
+					},
+				},
+				Timestamp: expiry,
+			},
+		},
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Style:    discordgo.SuccessButton,
+						CustomID: "vote_yes",
+						Emoji: &discordgo.ComponentEmoji{
+							Name: "\U0001F44D",
+						},
+					},
+					discordgo.Button{
+						Style:    discordgo.DangerButton,
+						CustomID: "vote_no",
+						Emoji: &discordgo.ComponentEmoji{
+							Name: "\U0001F44E",
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		req.Fail(fmt.Errorf("failed to send poll: %v", err))
+		return
+	}
+
+	err = createThreadWithTags(s, pollMsg.ChannelID, pollMsg.ID, reason, users)
+	if err != nil {
+		logger.Error("Thread creation failed", "err", err)
+	}
+
+	poll := &data.Poll{
+		MessageId: pollMsg.ID,
+		ChannelId: i.ChannelID,
+		CreatorId: i.Member.User.ID,
+		Points:    number,
+		Reason:    reason,
+		GainerIds: func() []string {
+			ids := make([]string, len(users))
+			for i, user := range users {
+				ids[i] = user.ID
+			}
+			return ids
+		}(),
+		Expiry: expiry,
+	}
+
+	data.CreatePoll(*poll)
+	metrics.PollsCreated.Inc()
+}
+
+func (ownCommand) Components() []string {
+	return []string{"vote_yes", "vote_no"}
+}
+
+// HandleComponent records a vote from the poll's buttons.
+func (ownCommand) HandleComponent(req *Request) {
+	i := req.Interaction
+	vote := i.MessageComponentData().CustomID == "vote_yes"
+	data.Vote(i.ChannelID, i.Message.ID, i.Member.User.ID, vote)
+	metrics.VotesCast.Inc()
+	req.Ephemeral("Vote recorded: " + map[bool]string{true: "👍", false: "👎"}[vote])
+}
+
+func formatUserMentions(users []*discordgo.User) string {
+	mentions := make([]string, len(users))
+	for i, user := range users {
+		mentions[i] = fmt.Sprintf("<@%s>", user.ID)
+	}
+	return strings.Join(mentions, "\n")
+}
+
+// Add new helper function
+func createThreadWithTags(s discord.Session, channelID string, messageID string, reason string, users []*discordgo.User) error {
+	thread, err := s.MessageThreadStartComplex(channelID, messageID, &discordgo.ThreadStart{
+		Name:                truncateString(reason, 100),
+		AutoArchiveDuration: 60,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create thread: %v", err)
+	}
+
+	// Create initial message tagging users
+	mentions := formatUserMentions(users)
+	_, err = s.ChannelMessageSend(thread.ID, mentions)
+	if err != nil {
+		return fmt.Errorf("failed to send initial thread message: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package inputs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Paginated embeds carry their state in button custom IDs of the form
+// prefix:page:state, so any instance of the bot can turn the page.
+
+func pageId(prefix string, page int, state string) string {
+	return prefix + ":" + strconv.Itoa(page) + ":" + state
+}
+
+func parsePageId(customId string) (prefix string, page int, state string, err error) {
+	parts := strings.SplitN(customId, ":", 3)
+	if len(parts) != 3 {
+		return "", 0, "", fmt.Errorf("invalid page id %q", customId)
+	}
+	page, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, "", fmt.Errorf("invalid page id %q", customId)
+	}
+	return parts[0], page, parts[2], nil
+}
+
+// pages is how many pages of size items take, at least one.
+func pages(items int, size int) int {
+	return max(1, (items+size-1)/size)
+}
+
+// clampPage keeps page within [0, pages).
+func clampPage(page int, pages int) int {
+	return min(max(page, 0), pages-1)
+}
+
+// pageButtons are the previous and next buttons for page, disabled at the
+// ends.
+func pageButtons(prefix string, page int, pages int, state string) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.Button{
+			Style:    discordgo.SecondaryButton,
+			Label:    "Previous",
+			CustomID: pageId(prefix, page-1, state),
+			Disabled: page <= 0,
+		},
+		discordgo.Button{
+			Style:    discordgo.SecondaryButton,
+			Label:    "Next",
+			CustomID: pageId(prefix, page+1, state),
+			Disabled: page >= pages-1,
+		},
+	}
+}
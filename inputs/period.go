@@ -0,0 +1,149 @@
+package inputs
+
+import (
+	"fmt"
+	"foulbot/data"
+	"foulbot/period"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// periodOption and the when, from and to options select a period.Period;
+// parsePeriod reads them back.
+func periodOption(description string) *discordgo.ApplicationCommandOption {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, len(period.KINDS))
+	for i, kind := range period.KINDS {
+		choices[i] = &discordgo.ApplicationCommandOptionChoice{Name: kind, Value: kind}
+	}
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "period",
+		Description: description,
+		Required:    false,
+		Choices:     choices,
+	}
+}
+
+var whenOption = &discordgo.ApplicationCommandOption{
+	Type:         discordgo.ApplicationCommandOptionString,
+	Name:         "when",
+	Description:  "Year, quarter, month or day in the period, e.g. 2024-Q2, or a season's name (defaults to now)",
+	Required:     false,
+	Autocomplete: true,
+}
+
+var fromOption = &discordgo.ApplicationCommandOption{
+	Type:        discordgo.ApplicationCommandOptionString,
+	Name:        "from",
+	Description: "First day of a custom period, YYYY-MM-DD",
+	Required:    false,
+}
+
+var toOption = &discordgo.ApplicationCommandOption{
+	Type:        discordgo.ApplicationCommandOptionString,
+	Name:        "to",
+	Description: "Last day of a custom period, YYYY-MM-DD",
+	Required:    false,
+}
+
+// parsePeriod reads the period options. Without any it is the current season,
+// or this year if no season is running. A season's name in when is enough to
+// pick that season.
+func parsePeriod(options []*discordgo.ApplicationCommandInteractionDataOption) (period.Period, error) {
+	var kind, when, from, to string
+	for _, option := range options {
+		switch option.Name {
+		case "period":
+			kind = option.StringValue()
+		case "when":
+			when = option.StringValue()
+		case "from":
+			from = option.StringValue()
+		case "to":
+			to = option.StringValue()
+		}
+	}
+	now := time.Now()
+	if kind == "" && when != "" && from == "" && to == "" {
+		if p, ok := seasonPeriod(when, now); ok {
+			return p, nil
+		}
+	}
+	if kind == period.Season || !hasPeriod(options) {
+		if p, ok := seasonPeriod(when, now); ok {
+			return p, nil
+		}
+		if kind == period.Season && when != "" {
+			return period.Period{}, fmt.Errorf("there is no season called %q, see /seasons", when)
+		}
+		if kind == period.Season {
+			return period.Period{}, fmt.Errorf("no season is running, pick one by name with when")
+		}
+	}
+	return period.Parse(kind, when, from, to, now)
+}
+
+// seasonPeriod is the season called name, or the one running at now if name
+// is empty.
+func seasonPeriod(name string, now time.Time) (period.Period, bool) {
+	season, ok := data.CurrentSeason(now)
+	if name != "" {
+		season, ok = data.SeasonByName(name)
+	}
+	if !ok {
+		return period.Period{}, false
+	}
+	return period.Period{Kind: period.Season, Name: season.Name, From: season.From, To: season.To}, true
+}
+
+// periodFromKey decodes a period.Key, looking up the name of seasons.
+func periodFromKey(key string) (period.Period, error) {
+	p, err := period.FromKey(key, time.Local)
+	if err != nil || p.Kind != period.Season {
+		return p, err
+	}
+	if season, ok := data.CurrentSeason(p.From); ok && season.From.Equal(p.From) {
+		p.Name = season.Name
+	}
+	return p, nil
+}
+
+// autocompletePeriod suggests season names for the when option, keeping
+// whatever was typed as the first choice so dates can still be entered.
+func autocompletePeriod(req *Request) {
+	var typed string
+	for _, option := range req.Interaction.ApplicationCommandData().Options {
+		if option.Focused && option.Name == "when" {
+			typed = option.StringValue()
+		}
+	}
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	if typed != "" {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: truncateString(typed, 100), Value: truncateString(typed, 100)})
+	}
+	for _, season := range data.Seasons() {
+		if len(choices) == 25 {
+			break
+		}
+		if season.Name != typed && strings.Contains(strings.ToLower(season.Name), strings.ToLower(typed)) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: season.Name, Value: season.Name})
+		}
+	}
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+}
+
+// hasPeriod reports whether any of the period options were given.
+func hasPeriod(options []*discordgo.ApplicationCommandInteractionDataOption) bool {
+	for _, option := range options {
+		switch option.Name {
+		case "period", "when", "from", "to":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package inputs
+
+import (
+	"fmt"
+	"foulbot/config"
+	"foulbot/data"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// adminPermissions is the DefaultMemberPermissions of commands that can
+// replace the bot or expose its data. Discord only shows them to members who
+// can manage the server, which configured admins can be given without full
+// Administrator, and authorized checks them again when invoked.
+var adminPermissions int64 = discordgo.PermissionManageServer
+
+func privileged(command Command) bool {
+	return command.Definition().DefaultMemberPermissions != nil
+}
+
+// isAdmin reports whether member may run privileged commands: anyone listed in
+// admin_user_ids, holding a role in admin_role_ids, or with the Administrator
+// permission in the guild.
+func isAdmin(cfg *config.Config, member *discordgo.Member) bool {
+	if member == nil || member.User == nil {
+		return false
+	}
+	if slices.Contains(cfg.AdminUserIDs, member.User.ID) {
+		return true
+	}
+	for _, role := range member.Roles {
+		if slices.Contains(cfg.AdminRoleIDs, role) {
+			return true
+		}
+	}
+	return member.Permissions&discordgo.PermissionAdministrator != 0
+}
+
+// authorized audits privileged commands and tells the user if they were
+// denied.
+func authorized(next Handler) Handler {
+	return func(req *Request) {
+		if !privileged(req.Command) {
+			next(req)
+			return
+		}
+
+		i := req.Interaction
+		allowed := isAdmin(req.Config, i.Member)
+		if i.Type == discordgo.InteractionApplicationCommandAutocomplete {
+			if allowed {
+				next(req)
+			}
+			return
+		}
+
+		userId := ""
+		if i.Member != nil && i.Member.User != nil {
+			userId = i.Member.User.ID
+		}
+		name := req.Command.Definition().Name
+		if i.Type == discordgo.InteractionApplicationCommand {
+			auditOptions(userId, name, i.ApplicationCommandData().Options, allowed)
+		} else {
+			data.Audit(userId, name, i.MessageComponentData().CustomID, allowed)
+		}
+		if !allowed {
+			req.Logger.Warn("Privileged command denied")
+			req.Ephemeral(fmt.Sprintf("You don't have permission to use /%s.", name))
+			return
+		}
+		req.Logger.Info("Privileged command allowed")
+		next(req)
+	}
+}
+
+func auditOptions(userId string, command string, options []*discordgo.ApplicationCommandInteractionDataOption, allowed bool) {
+	data.Audit(userId, command, summarizeOptions(options), allowed)
+}
+
+func summarizeOptions(options []*discordgo.ApplicationCommandInteractionDataOption) string {
+	summary := make([]string, len(options))
+	for i, option := range options {
+		if option.Type == discordgo.ApplicationCommandOptionSubCommand {
+			summary[i] = strings.TrimSpace(option.Name + " " + summarizeOptions(option.Options))
+		} else {
+			summary[i] = fmt.Sprintf("%s=%v", option.Name, option.Value)
+		}
+	}
+	return strings.Join(summary, " ")
+}
@@ -0,0 +1,119 @@
+package inputs
+
+import (
+	"fmt"
+	"foulbot/config"
+	"foulbot/data"
+	"foulbot/discord"
+	"foulbot/lifecycle"
+	"foulbot/updater"
+	"log/slog"
+	"os"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// restoreCommand replaces the database with an uploaded /logs backup.
+type restoreCommand struct{}
+
+func (restoreCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:                     "restore",
+		Description:              "Replace the database with a backup from /logs",
+		DefaultMemberPermissions: &adminPermissions,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionAttachment,
+				Name:        "backup",
+				Description: "The foulbot-db.zip to restore",
+				Required:    true,
+			},
+		},
+	}
+}
+
+func (restoreCommand) Handle(req *Request) {
+	i, cfg := req.Interaction, req.Config
+	options := i.ApplicationCommandData().Options
+
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	attachment := i.ApplicationCommandData().Resolved.Attachments[options[0].Value.(string)]
+	zipPath, err := downloadAttachment(attachment.URL)
+	if err != nil {
+		req.Followup(&discordgo.WebhookParams{
+			Content: fmt.Sprintf("Failed to download backup: %s", err),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return
+	}
+	defer os.Remove(zipPath)
+
+	err = data.StageRestore(zipPath)
+	if err != nil {
+		req.Followup(&discordgo.WebhookParams{
+			Content: fmt.Sprintf("Restore failed: %s", err),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return
+	}
+	req.Followup(&discordgo.WebhookParams{
+		Content: fmt.Sprintf("%s is valid, restarting to restore it...", attachment.Filename),
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+
+	// The restart has to wait for this handler to finish, so it cannot run
+	// inside it
+	go restartToRestore(req.Session, i, req.Tracker, attachment.Filename, cfg.BackupDir, req.Logger)
+}
+
+// restartToRestore waits for in-flight work, snapshots the current database
+// and restarts so the staged restore is put in place by the next Open. If
+// in-flight work does not finish, the restore is cancelled and the bot carries
+// on.
+func restartToRestore(s discord.Session, i *discordgo.InteractionCreate, tracker *lifecycle.Tracker, name string, backupDir string, logger *slog.Logger) {
+	if !tracker.Drain(config.SHUTDOWN_TIMEOUT) {
+		data.CancelRestore()
+		tracker.Resume()
+		logger.Error("Restore cancelled, in-flight work did not finish", "timeout", config.SHUTDOWN_TIMEOUT)
+		followup(logger, s, i, &discordgo.WebhookParams{
+			Content: fmt.Sprintf("Restore of %s was cancelled: in-flight work did not finish in time.", name),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return
+	}
+
+	safety, err := data.SafetyBackup(backupDir)
+	if err != nil {
+		data.CancelRestore()
+		tracker.Resume()
+		logger.Error("Restore cancelled", "err", err)
+		followup(logger, s, i, &discordgo.WebhookParams{
+			Content: fmt.Sprintf("Restore of %s was cancelled: %s", name, err),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return
+	}
+	followup(logger, s, i, &discordgo.WebhookParams{
+		Content: fmt.Sprintf("Restoring %s. The previous database was saved to `%s`.", name, safety),
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+
+	s.Close()
+	err = data.Close()
+	if err != nil {
+		logger.Error("Failed to close database for restore", "err", err)
+	}
+	err = updater.Restart()
+	if err != nil {
+		// The restore stays staged and is applied on the next start
+		logger.Error("Failed to restart for restore", "err", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
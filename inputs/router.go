@@ -0,0 +1,202 @@
+package inputs
+
+import (
+	"context"
+	"foulbot/config"
+	"foulbot/discord"
+	"foulbot/lifecycle"
+	"foulbot/logging"
+	"log/slog"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Command is a slash command: its definition, registered with Discord, and
+// the handler that runs when it is invoked. Commands may also implement
+// Autocompleter and ComponentHandler.
+type Command interface {
+	Definition() *discordgo.ApplicationCommand
+	Handle(req *Request)
+}
+
+// Autocompleter suggests option values while a command is being typed.
+type Autocompleter interface {
+	Autocomplete(req *Request)
+}
+
+// ComponentHandler handles the buttons on a command's messages. Custom IDs
+// are matched on the part before the first ':', the rest is free for the
+// command to encode state in.
+type ComponentHandler interface {
+	Components() []string
+	HandleComponent(req *Request)
+}
+
+// Request is a single interaction being handled.
+type Request struct {
+	Ctx         context.Context
+	Session     discord.Session
+	Interaction *discordgo.InteractionCreate
+	Config      *config.Config
+	Logger      *slog.Logger
+	// Tracker counts in-flight work; draining it pauses the scheduler and
+	// every other handler.
+	Tracker *lifecycle.Tracker
+	// Command is the command the interaction belongs to.
+	Command Command
+
+	responded bool
+}
+
+type Handler func(req *Request)
+
+// Middleware wraps every handler the router runs.
+type Middleware func(next Handler) Handler
+
+// Router dispatches interactions to registered commands through its
+// middleware, outermost first.
+type Router struct {
+	cfg        *config.Config
+	tracker    *lifecycle.Tracker
+	commands   map[string]Command
+	order      []string
+	components map[string]Command
+	middleware []Middleware
+}
+
+func NewRouter(cfg *config.Config) *Router {
+	return &Router{
+		cfg:        cfg,
+		commands:   make(map[string]Command),
+		components: make(map[string]Command),
+	}
+}
+
+// Default is the router for every command foulbot provides.
+func Default(cfg *config.Config, tracker *lifecycle.Tracker) *Router {
+	router := NewRouter(cfg)
+	router.tracker = tracker
+	router.Use(recovering, tracking(tracker), logged, counted, authorized)
+	router.Register(
+		ownCommand{},
+		leaderboardCommand{},
+		versionCommand{},
+		updateCommand{},
+		logsCommand{},
+		restoreCommand{},
+		importCommand{},
+		exportCommand{},
+		outboxCommand{},
+		statusCommand{},
+		historyCommand{},
+		searchCommand{},
+		versusCommand{},
+		seasonCommand{},
+		seasonsCommand{},
+	)
+	return router
+}
+
+func (r *Router) Use(middleware ...Middleware) {
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// Register adds commands and their component handlers. Registering two
+// commands or components under the same name panics.
+func (r *Router) Register(commands ...Command) {
+	for _, command := range commands {
+		name := command.Definition().Name
+		if _, ok := r.commands[name]; ok {
+			panic("duplicate command " + name)
+		}
+		r.commands[name] = command
+		r.order = append(r.order, name)
+
+		if handler, ok := command.(ComponentHandler); ok {
+			for _, id := range handler.Components() {
+				if _, ok := r.components[id]; ok {
+					panic("duplicate component " + id)
+				}
+				r.components[id] = command
+			}
+		}
+	}
+}
+
+// Definitions returns the registered commands' definitions, in registration
+// order, for ApplicationCommandBulkOverwrite.
+func (r *Router) Definitions() []*discordgo.ApplicationCommand {
+	definitions := make([]*discordgo.ApplicationCommand, len(r.order))
+	for i, name := range r.order {
+		definitions[i] = r.commands[name].Definition()
+	}
+	return definitions
+}
+
+// Handle runs the handler for an interaction. Unknown commands and components
+// are logged and ignored.
+func (r *Router) Handle(ctx context.Context, s discord.Session, i *discordgo.InteractionCreate) {
+	req := &Request{
+		Ctx:         ctx,
+		Session:     s,
+		Interaction: i,
+		Config:      r.cfg,
+		Logger:      logging.ForInteraction(i),
+		Tracker:     r.tracker,
+	}
+
+	var handler Handler
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		req.Command = r.commands[i.ApplicationCommandData().Name]
+		if req.Command != nil {
+			handler = req.Command.Handle
+		}
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		req.Command = r.commands[i.ApplicationCommandData().Name]
+		if autocompleter, ok := req.Command.(Autocompleter); ok {
+			handler = autocompleter.Autocomplete
+		}
+	case discordgo.InteractionMessageComponent:
+		id, _, _ := strings.Cut(i.MessageComponentData().CustomID, ":")
+		if command, ok := r.components[id]; ok {
+			req.Command = command
+			handler = command.(ComponentHandler).HandleComponent
+		}
+	default:
+		return
+	}
+	if handler == nil {
+		req.Logger.Warn("No handler for interaction")
+		return
+	}
+
+	for j := len(r.middleware) - 1; j >= 0; j-- {
+		handler = r.middleware[j](handler)
+	}
+	handler(req)
+}
+
+// Respond sends the interaction response, logging rather than dropping
+// failures.
+func (req *Request) Respond(resp *discordgo.InteractionResponse) {
+	req.responded = true
+	respond(req.Logger, req.Session, req.Interaction, resp)
+}
+
+// Followup sends a followup message, logging rather than dropping failures.
+func (req *Request) Followup(params *discordgo.WebhookParams) {
+	followup(req.Logger, req.Session, req.Interaction, params)
+}
+
+// Ephemeral responds with a message only the invoking user can see.
+func (req *Request) Ephemeral(content string) {
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
@@ -0,0 +1,150 @@
+package inputs
+
+import (
+	"fmt"
+	"foulbot/config"
+	"foulbot/data"
+	"foulbot/period"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// searchCommand finds polls by the words in their reason.
+type searchCommand struct{}
+
+// savedSearch is what /search saves for its page buttons.
+type savedSearch struct {
+	Filter data.SearchFilter `json:"filter"`
+	Period string            `json:"period"`
+}
+
+func (searchCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        "search",
+		Description: "Search poll reasons",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "query",
+				Description: "Words to look for, partial words match too",
+				Required:    true,
+			},
+			periodOption("Period to search (defaults to all time)"),
+			whenOption,
+			fromOption,
+			toOption,
+		},
+	}
+}
+
+func (searchCommand) Handle(req *Request) {
+	options := req.Interaction.ApplicationCommandData().Options
+
+	p, err := parsePeriod(options)
+	if err != nil {
+		req.Ephemeral(fmt.Sprintf("Invalid period: %s", err))
+		return
+	}
+	if !hasPeriod(options) {
+		p, _ = period.Parse(period.AllTime, "", "", "", time.Now())
+	}
+	search := savedSearch{Filter: data.SearchFilter{From: p.From, To: p.To}, Period: p.Name}
+	for _, option := range options {
+		if option.Name == "query" {
+			search.Filter.Query = option.StringValue()
+		}
+	}
+	if strings.TrimSpace(search.Filter.Query) == "" {
+		req.Ephemeral("Give some words to search for.")
+		return
+	}
+
+	id := data.SaveSearch("search", search)
+	embed, components := searchPage(id, search, 0, req.Interaction.GuildID)
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// Autocomplete suggests seasons for the when option.
+func (searchCommand) Autocomplete(req *Request) {
+	autocompletePeriod(req)
+}
+
+func (searchCommand) Components() []string {
+	return []string{"search_page"}
+}
+
+// HandleComponent turns the page. The state is the saved search id.
+func (searchCommand) HandleComponent(req *Request) {
+	_, page, state, err := parsePageId(req.Interaction.MessageComponentData().CustomID)
+	if err != nil {
+		req.Fail(err)
+		return
+	}
+	id, err := strconv.ParseInt(state, 10, 64)
+	if err != nil {
+		req.Fail(fmt.Errorf("invalid search id %q", state))
+		return
+	}
+	var search savedSearch
+	if !data.LoadSearch(id, "search", &search) {
+		req.Ephemeral("This search has expired, run /search again.")
+		return
+	}
+
+	embed, components := searchPage(id, search, page, req.Interaction.GuildID)
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+}
+
+func searchPage(id int64, search savedSearch, page int, guildId string) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	page = max(page, 0)
+	results, total := data.Search(search.Filter, page*config.PAGE_SIZE, config.PAGE_SIZE)
+	count := pages(total, config.PAGE_SIZE)
+	if page >= count {
+		page = count - 1
+		results, total = data.Search(search.Filter, page*config.PAGE_SIZE, config.PAGE_SIZE)
+	}
+
+	var b strings.Builder
+	for _, result := range results {
+		fmt.Fprintf(&b, "`%s` **%+d** %s, %s %s\n", pollDate(result.PollSummary), result.Points, result.Snippet,
+			outcome(result.PollSummary), jumpLink(guildId, result.PollSummary))
+	}
+	if total == 0 {
+		b.WriteString("No polls found.")
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       truncateString(fmt.Sprintf("Search for %q, %s", search.Filter.Query, search.Period), 256),
+		Description: truncateString(b.String(), 4096),
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("%s, page %d of %d", plural(int64(total), "poll"), page+1, count)},
+	}
+	if count == 1 {
+		return embed, nil
+	}
+	buttons := pageButtons("search_page", page, count, strconv.FormatInt(id, 10))
+	return embed, []discordgo.MessageComponent{discordgo.ActionsRow{Components: buttons}}
+}
+
+// jumpLink links to a poll's message, which imported polls don't have.
+func jumpLink(guildId string, poll data.PollSummary) string {
+	if poll.ChannelId == data.ImportChannelId {
+		return "(imported)"
+	}
+	return fmt.Sprintf("[jump](https://discord.com/channels/%s/%s/%s)", guildId, poll.ChannelId, poll.MessageId)
+}
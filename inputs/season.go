@@ -0,0 +1,195 @@
+package inputs
+
+import (
+	"fmt"
+	"foulbot/awards"
+	"foulbot/data"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// seasonCommand lets admins start and end the seasons that leaderboards,
+// statuses and the awards follow.
+type seasonCommand struct{}
+
+const seasonDateLayout = "2006-01-02"
+
+func (seasonCommand) Definition() *discordgo.ApplicationCommand {
+	dateOption := func(description string) *discordgo.ApplicationCommandOption {
+		return &discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "date",
+			Description: description,
+			Required:    false,
+		}
+	}
+	return &discordgo.ApplicationCommand{
+		Name:                     "season",
+		Description:              "Manage seasons",
+		DefaultMemberPermissions: &adminPermissions,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "start",
+				Description: "Start a season, ending the current one",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "What to call the season",
+						Required:    true,
+					},
+					dateOption("Day the season starts, YYYY-MM-DD (defaults to now)"),
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "end",
+				Description: "End the current season, after which its awards are handed out",
+				Options: []*discordgo.ApplicationCommandOption{
+					dateOption("Day after the season's last day, YYYY-MM-DD (defaults to now)"),
+				},
+			},
+		},
+	}
+}
+
+func (seasonCommand) Handle(req *Request) {
+	subcommand := req.Interaction.ApplicationCommandData().Options[0]
+
+	name, at := "", time.Now()
+	for _, option := range subcommand.Options {
+		switch option.Name {
+		case "name":
+			name = strings.TrimSpace(option.StringValue())
+		case "date":
+			date, err := time.ParseInLocation(seasonDateLayout, option.StringValue(), time.Local)
+			if err != nil {
+				req.Ephemeral(fmt.Sprintf("Invalid date %q, expected YYYY-MM-DD.", option.StringValue()))
+				return
+			}
+			at = date
+		}
+	}
+
+	var content string
+	if subcommand.Name == "start" {
+		content = startSeason(name, at)
+	} else {
+		content = endSeason(at)
+	}
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Content: content},
+	})
+}
+
+// seasonsCommand lists the seasons for everyone, so past ones can be found
+// and browsed.
+type seasonsCommand struct{}
+
+func (seasonsCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        "seasons",
+		Description: "List every season",
+	}
+}
+
+func (seasonsCommand) Handle(req *Request) {
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{{Title: "Seasons", Description: formatSeasons(data.Seasons(), time.Now())}},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// startSeason starts a season called name at at, ending the latest season
+// there if it is still running. Seasons can't start before the latest one.
+func startSeason(name string, at time.Time) string {
+	if name == "" {
+		return "Give the season a name."
+	}
+	if _, ok := data.SeasonByName(name); ok {
+		return fmt.Sprintf("There is already a season called %s.", name)
+	}
+
+	ended, ending := "", int64(0)
+	if latest, ok := data.LatestSeason(); ok {
+		if !at.After(latest.From) {
+			return fmt.Sprintf("%s started on %s, so a new season has to start after that.", latest.Name, formatSeasonTime(latest.From))
+		}
+		if latest.To.After(at) {
+			if latest.Archived {
+				return fmt.Sprintf("%s has been archived and ends on %s, so a new season can't start before then.", latest.Name, formatSeasonTime(latest.To))
+			}
+			ended, ending = fmt.Sprintf(", ending %s", latest.Name), latest.Id
+		}
+	}
+	if !data.StartSeason(name, at, data.OpenEnded, ending) {
+		return fmt.Sprintf("There is already a season called %s.", name)
+	}
+	return fmt.Sprintf("Season %s starts on %s%s.", name, formatSeasonTime(at), ended)
+}
+
+// endSeason ends the season running at at.
+func endSeason(at time.Time) string {
+	season, ok := data.CurrentSeason(at)
+	if !ok {
+		return "No season is running then."
+	}
+	if season.Archived {
+		return fmt.Sprintf("%s has already been archived.", season.Name)
+	}
+	if !at.After(season.From) {
+		return fmt.Sprintf("%s started on %s, so it has to end after that.", season.Name, formatSeasonTime(season.From))
+	}
+	data.EndSeason(season.Id, at)
+	return fmt.Sprintf("Season %s ends on %s. Its awards are handed out once its last polls close.", season.Name, formatSeasonTime(at))
+}
+
+func formatSeasons(seasons []data.Season, now time.Time) string {
+	if len(seasons) == 0 {
+		return "No seasons yet. Start one with `/season start`."
+	}
+	var b strings.Builder
+	for _, season := range seasons {
+		span := fmt.Sprintf("%s to %s", formatSeasonTime(season.From), formatSeasonTime(season.To))
+		if season.To.Equal(data.OpenEnded) {
+			span = fmt.Sprintf("from %s", formatSeasonTime(season.From))
+		}
+		state := ""
+		switch {
+		case season.Archived:
+			state = "archived"
+			if winners := awards.Winners(data.Standings(season.Id)); len(winners) > 0 {
+				state += ", won by <@" + strings.Join(winners, "> <@") + ">"
+			}
+		case now.Before(season.From):
+			state = "upcoming"
+		case now.Before(season.To):
+			state = "current"
+		default:
+			state = "ended, awards pending"
+		}
+		line := fmt.Sprintf("**%s** %s, %s\n", season.Name, span, state)
+		if b.Len()+len(line) > 4000 {
+			b.WriteString("...")
+			break
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// formatSeasonTime shows the day, and the time too unless it is midnight.
+func formatSeasonTime(t time.Time) string {
+	t = t.Local()
+	if t.Hour() == 0 && t.Minute() == 0 {
+		return t.Format("Jan 2 2006")
+	}
+	return t.Format("Jan 2 2006 15:04")
+}
@@ -0,0 +1,210 @@
+package inputs
+
+import (
+	"bytes"
+	"fmt"
+	"foulbot/chart"
+	"foulbot/config"
+	"foulbot/data"
+	"foulbot/discord"
+	"foulbot/period"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// statusCommand shows a member's points, rank and history for a period.
+type statusCommand struct{}
+
+func (statusCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        "status",
+		Description: "Displays how many points a user has",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user",
+				Description: "The user to check",
+				Required:    true,
+			},
+			periodOption("Period to show status for (defaults to the current season or this year)"),
+			whenOption,
+			fromOption,
+			toOption,
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "chart",
+				Description: "Attach a chart of points by month",
+				Required:    false,
+			},
+		},
+	}
+}
+
+func (statusCommand) Handle(req *Request) {
+	i := req.Interaction
+	options := i.ApplicationCommandData().Options
+
+	p, err := parsePeriod(options)
+	if err != nil {
+		req.Ephemeral(fmt.Sprintf("Invalid period: %s", err))
+		return
+	}
+	user := options[0].UserValue(nil)
+	if user == nil {
+		user = i.Member.User
+	}
+
+	embed, components := statusPage(user.ID, p, 0, i.GuildID)
+	var files []*discordgo.File
+	for _, option := range options {
+		if option.Name == "chart" && option.BoolValue() {
+			file, err := statusChart(req.Session, i.GuildID, user.ID, p)
+			if err != nil {
+				req.Fail(fmt.Errorf("failed to draw status chart: %v", err))
+				return
+			}
+			embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://" + file.Name}
+			files = append(files, file)
+		}
+	}
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+			Files:      files,
+		},
+	})
+}
+
+// Autocomplete suggests seasons for the when option.
+func (statusCommand) Autocomplete(req *Request) {
+	autocompletePeriod(req)
+}
+
+func (statusCommand) Components() []string {
+	return []string{"status_page"}
+}
+
+// HandleComponent turns the page. The state is the member and period key.
+func (statusCommand) HandleComponent(req *Request) {
+	i := req.Interaction
+	_, page, state, err := parsePageId(i.MessageComponentData().CustomID)
+	if err != nil {
+		req.Fail(err)
+		return
+	}
+	userId, key, _ := strings.Cut(state, ":")
+	p, err := periodFromKey(key)
+	if err != nil {
+		req.Fail(err)
+		return
+	}
+
+	embed, components := statusPage(userId, p, page, i.GuildID)
+	keepImage(embed, i.Message)
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+}
+
+// statusPage renders one page of a member's status: an overview, then the
+// breakdowns and recent polls if there are any.
+func statusPage(userId string, p period.Period, page int, guildId string) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	report := data.Report(userId, p.From, p.To, config.STATUS_LIST_LENGTH)
+
+	rank := "Unranked"
+	if report.Rank > 0 {
+		rank = fmt.Sprintf("#%d of %d", report.Rank, report.Ranked)
+	}
+	biggest := "None"
+	if report.Biggest != nil {
+		biggest = fmt.Sprintf("%+d %s", report.Biggest.Points, pollLink(guildId, *report.Biggest))
+	}
+	coGainers := make([]string, len(report.CoGainers))
+	for i, count := range report.CoGainers {
+		coGainers[i] = fmt.Sprintf("<@%s> (%s)", count.Key, plural(int64(count.Polls), "poll"))
+	}
+	pages := [][]*discordgo.MessageEmbedField{{
+		{Name: "Points", Value: fmt.Sprintf("%d", report.Points), Inline: true},
+		{Name: "Rank", Value: rank, Inline: true},
+		{Name: "Polls", Value: fmt.Sprintf("%d passed, %d failed, %d open", report.Passed, report.Failed, report.Pending), Inline: true},
+		{Name: "Biggest foul", Value: biggest},
+		{Name: "Most often owned with", Value: orNone(strings.Join(coGainers, "\n"))},
+	}}
+
+	if len(report.Reasons) > 0 || len(report.Months) > 0 {
+		reasons := make([]string, len(report.Reasons))
+		for i, count := range report.Reasons {
+			reasons[i] = fmt.Sprintf("%s: %s, %d points", truncateString(count.Key, 60), plural(int64(count.Polls), "poll"), count.Points)
+		}
+		months := make([]string, 0, len(report.Months))
+		for _, count := range report.Months[max(0, len(report.Months)-12):] {
+			months = append(months, fmt.Sprintf("%s: %d", count.Key, count.Points))
+		}
+		pages = append(pages, []*discordgo.MessageEmbedField{
+			{Name: "Reasons", Value: orNone(strings.Join(reasons, "\n"))},
+			{Name: "Points by month", Value: orNone(strings.Join(months, "\n"))},
+		})
+	}
+
+	if len(report.Recent) > 0 {
+		recent := make([]string, len(report.Recent))
+		for i, poll := range report.Recent {
+			outcome := "open"
+			if poll.Passed.Valid {
+				outcome = map[bool]string{true: "passed", false: "failed"}[poll.Passed.Bool]
+			}
+			recent[i] = fmt.Sprintf("%+d %s, %s", poll.Points, pollLink(guildId, poll), outcome)
+		}
+		pages = append(pages, []*discordgo.MessageEmbedField{
+			{Name: "Recent polls", Value: strings.Join(recent, "\n")},
+		})
+	}
+
+	page = clampPage(page, len(pages))
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Status %s", p.Name),
+		Description: fmt.Sprintf("<@%s>", userId),
+		Fields:      pages[page],
+	}
+	if len(pages) == 1 {
+		return embed, nil
+	}
+	embed.Footer = &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Page %d of %d", page+1, len(pages))}
+	buttons := pageButtons("status_page", page, len(pages), userId+":"+p.Key())
+	return embed, []discordgo.MessageComponent{discordgo.ActionsRow{Components: buttons}}
+}
+
+// statusChart draws a member's points by month for the last year of p.
+func statusChart(s discord.Session, guildId string, userId string, p period.Period) (*discordgo.File, error) {
+	months := data.Report(userId, p.From, p.To, 0).Months
+	bars := make([]chart.Bar, 0, 12)
+	for _, count := range months[max(0, len(months)-12):] {
+		bars = append(bars, chart.Bar{Label: count.Key, Value: float64(count.Points)})
+	}
+	var image bytes.Buffer
+	err := chart.Histogram(&image, fmt.Sprintf("%s, points by month, %s", memberName(s, guildId, userId), p.Name), bars)
+	return &discordgo.File{Name: "status.png", ContentType: "image/png", Reader: &image}, err
+}
+
+// pollLink is the poll's reason linking to its message, if it has one.
+func pollLink(guildId string, poll data.PollSummary) string {
+	reason := truncateString(poll.Reason, 80)
+	if poll.ChannelId == data.ImportChannelId {
+		return reason + " (imported)"
+	}
+	return fmt.Sprintf("[%s](https://discord.com/channels/%s/%s/%s)", reason, guildId, poll.ChannelId, poll.MessageId)
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "None"
+	}
+	return s
+}
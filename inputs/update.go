@@ -0,0 +1,171 @@
+package inputs
+
+import (
+	"fmt"
+	"foulbot/config"
+	"foulbot/discord"
+	"foulbot/lifecycle"
+	"foulbot/updater"
+	"log/slog"
+	"os"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// updateCommand checks for and installs releases.
+type updateCommand struct{}
+
+func (updateCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:                     "update",
+		Description:              "Update the bot to a new version",
+		DefaultMemberPermissions: &adminPermissions,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "install",
+				Description: "Install the latest release",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "force",
+						Description: "Install the latest release even if it is not newer",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "check",
+				Description: "Compare the running version to the latest release",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "to",
+				Description: "Install a specific release, even if it is older",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "version",
+						Description: "The release to install",
+						Required:    true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (updateCommand) Handle(req *Request) {
+	s, i, logger, cfg := req.Session, req.Interaction, req.Logger, req.Config
+	options := i.ApplicationCommandData().Options
+
+	subcommand := options[0]
+	if subcommand.Name == "check" {
+		req.Respond(&discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: checkForUpdate(cfg.ReleasesURL),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	version, force := "", false
+	for _, option := range subcommand.Options {
+		switch option.Name {
+		case "version":
+			version = option.StringValue()
+		case "force":
+			force = option.BoolValue()
+		}
+	}
+
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: "Attempting to update...",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	to, err := updater.Update(cfg.ReleasesURL, version, force)
+	if err == updater.ErrNotNewer {
+		req.Followup(&discordgo.WebhookParams{
+			Content: fmt.Sprintf("Already up to date: running %s, latest release is %s. Use force to reinstall or downgrade.", config.VERSION, to),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return
+	}
+	if err != nil {
+		req.Followup(&discordgo.WebhookParams{
+			Content: fmt.Sprintf("Update from %s to %s failed: %s", config.VERSION, to, err),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		return
+	}
+
+	req.Followup(&discordgo.WebhookParams{
+		Content: fmt.Sprintf("Installed %s, restarting bot...", to),
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+
+	run_migrations()
+
+	// The handover has to wait for this handler to finish, so it cannot run
+	// inside it
+	go handOver(s, i.ChannelID, req.Tracker, to, logger)
+}
+
+// handOver pauses the scheduler and handlers, then hands the gateway to the
+// installed binary and exits once it reports healthy. If it does not, the
+// previous binary is back in place and this process carries on.
+func handOver(s discord.Session, channelId string, tracker *lifecycle.Tracker, to string, logger *slog.Logger) {
+	if !tracker.Drain(config.SHUTDOWN_TIMEOUT) {
+		tracker.Resume()
+		err := updater.Rollback()
+		logger.Error("Update cancelled, in-flight work did not finish", "to", to, "timeout", config.SHUTDOWN_TIMEOUT, "rollback_err", err)
+		send(s, channelId, fmt.Sprintf("Update to %s was cancelled: in-flight work did not finish in time.", to), logger)
+		return
+	}
+
+	// Keep this process around to roll back if the new one fails to come up
+	s.Close()
+	err := updater.StartVerified(config.UPDATE_HEALTH_TIMEOUT)
+	if err == nil {
+		send(s, channelId, fmt.Sprintf("Updated from %s to %s.", config.VERSION, to), logger)
+		os.Exit(0)
+	}
+
+	logger.Error("Update failed to start", "from", config.VERSION, "to", to, "err", err)
+	send(s, channelId, fmt.Sprintf("Update to %s failed to start, rolled back to %s: %s", to, config.VERSION, err), logger)
+	if err := s.Open(); err != nil {
+		logger.Error("Failed to reconnect after rollback", "err", err)
+	}
+	tracker.Resume()
+}
+
+// send posts content to a channel, logging rather than dropping failures.
+func send(s discord.Session, channelId string, content string, logger *slog.Logger) {
+	_, err := s.ChannelMessageSend(channelId, content)
+	if err != nil {
+		logger.Error("Failed to send message", "channel", channelId, "err", err)
+	}
+}
+
+// checkForUpdate describes how the running version compares to the latest
+// release.
+func checkForUpdate(releasesURL string) string {
+	latest, err := updater.LatestVersion(releasesURL)
+	if err != nil {
+		return fmt.Sprintf("Failed to check for updates: %s", err)
+	}
+	if updater.CompareVersions(latest, config.VERSION) > 0 {
+		return fmt.Sprintf("Version %s is available (running %s). Use `/update install` to install it.", latest, config.VERSION)
+	}
+	return fmt.Sprintf("Up to date: running %s, latest release is %s.", config.VERSION, latest)
+}
+
+func run_migrations() {
+}
@@ -0,0 +1,29 @@
+package inputs
+
+import (
+	"fmt"
+	"foulbot/config"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// versionCommand shows the running version.
+type versionCommand struct{}
+
+func (versionCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        "version",
+		Description: "Displays the current version",
+		Options:     []*discordgo.ApplicationCommandOption{},
+	}
+}
+
+func (versionCommand) Handle(req *Request) {
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Current version: %s", config.VERSION),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
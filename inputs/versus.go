@@ -0,0 +1,142 @@
+package inputs
+
+import (
+	"bytes"
+	"fmt"
+	"foulbot/chart"
+	"foulbot/config"
+	"foulbot/data"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// versusCommand compares two members head to head.
+type versusCommand struct{}
+
+func (versusCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        "versus",
+		Description: "Compare two members head to head",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user1",
+				Description: "The first user",
+				Required:    true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user2",
+				Description: "The second user",
+				Required:    true,
+			},
+			periodOption("Period to compare (defaults to the current season or this year)"),
+			whenOption,
+			fromOption,
+			toOption,
+		},
+	}
+}
+
+func (versusCommand) Handle(req *Request) {
+	i := req.Interaction
+	options := i.ApplicationCommandData().Options
+
+	p, err := parsePeriod(options)
+	if err != nil {
+		req.Ephemeral(fmt.Sprintf("Invalid period: %s", err))
+		return
+	}
+	var ids [2]string
+	for _, option := range options {
+		switch option.Name {
+		case "user1":
+			ids[0] = option.UserValue(nil).ID
+		case "user2":
+			ids[1] = option.UserValue(nil).ID
+		}
+	}
+	if ids[0] == ids[1] {
+		req.Ephemeral("Pick two different users.")
+		return
+	}
+
+	var names [2]string
+	var reports [2]data.StatusReport
+	var events [2][]chart.Point
+	for n, id := range ids {
+		names[n] = memberName(req.Session, i.GuildID, id)
+		reports[n] = data.Report(id, p.From, p.To, config.STATUS_LIST_LENGTH)
+		for _, gain := range data.Timeline(id, p.From, p.To) {
+			events[n] = append(events[n], chart.Point{Time: gain.Time, Value: float64(gain.Points)})
+		}
+	}
+
+	from, to := chartRange(p, events[0], events[1])
+	series := []chart.Series{
+		chart.Cumulative(names[0], from, to, events[0]),
+		chart.Cumulative(names[1], from, to, events[1]),
+	}
+	var image bytes.Buffer
+	err = chart.Line(&image, fmt.Sprintf("Points, %s", p.Name), from, to, series)
+	if err != nil {
+		req.Fail(err)
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       truncateString(fmt.Sprintf("%s vs %s, %s", names[0], names[1], p.Name), 256),
+		Description: verdict(ids, reports),
+		Image:       &discordgo.MessageEmbedImage{URL: "attachment://versus.png"},
+	}
+	for n := range ids {
+		other := ids[1-n]
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   names[n],
+			Value:  versusSummary(reports[n], data.Scrutinize(ids[n], other, p.From, p.To), other),
+			Inline: true,
+		})
+	}
+
+	req.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{embed},
+			Files:           []*discordgo.File{{Name: "versus.png", ContentType: "image/png", Reader: &image}},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
+
+// Autocomplete suggests seasons for the when option.
+func (versusCommand) Autocomplete(req *Request) {
+	autocompletePeriod(req)
+}
+
+func verdict(ids [2]string, reports [2]data.StatusReport) string {
+	difference := reports[0].Points - reports[1].Points
+	switch {
+	case difference > 0:
+		return fmt.Sprintf("<@%s> is ahead of <@%s> by %s.", ids[0], ids[1], plural(difference, "point"))
+	case difference < 0:
+		return fmt.Sprintf("<@%s> is ahead of <@%s> by %s.", ids[1], ids[0], plural(-difference, "point"))
+	}
+	return fmt.Sprintf("<@%s> and <@%s> are tied on %s.", ids[0], ids[1], plural(reports[0].Points, "point"))
+}
+
+// versusSummary describes one side of the comparison, including how they
+// treated the polls otherId gained in.
+func versusSummary(report data.StatusReport, scrutiny data.Scrutiny, otherId string) string {
+	rank := "unranked"
+	if report.Rank > 0 {
+		rank = fmt.Sprintf("#%d of %d", report.Rank, report.Ranked)
+	}
+	passRate := "n/a"
+	if decided := report.Passed + report.Failed; decided > 0 {
+		passRate = fmt.Sprintf("%d%%", report.Passed*100/decided)
+	}
+	return fmt.Sprintf("**Points** %d (%s)\n**Polls** %d passed, %d failed, %d open\n**Pass rate** %s\n"+
+		"**On <@%s>'s %s** created %d, voted 👍 %d 👎 %d",
+		report.Points, rank, report.Passed, report.Failed, report.Pending, passRate,
+		otherId, plural(int64(scrutiny.Polls), "poll"), scrutiny.Created, scrutiny.For, scrutiny.Against)
+}
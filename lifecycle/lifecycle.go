@@ -0,0 +1,58 @@
+package lifecycle
+
+import (
+	"sync"
+	"time"
+)
+
+// Tracker counts in-flight work so shutdown can wait for it to finish. Once
+// Drain has been called no new work is admitted.
+type Tracker struct {
+	mu       sync.Mutex
+	draining bool
+	wg       sync.WaitGroup
+}
+
+// Start admits a unit of work, returning false if the tracker is draining.
+// Every successful Start must be paired with Done.
+func (t *Tracker) Start() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return false
+	}
+	t.wg.Add(1)
+	return true
+}
+
+func (t *Tracker) Done() {
+	t.wg.Done()
+}
+
+// Drain stops admitting work and waits up to timeout for in-flight work to
+// finish. It reports whether everything finished in time.
+func (t *Tracker) Drain(timeout time.Duration) bool {
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// Resume admits work again after a Drain that was not followed by shutdown,
+// such as a restart that could not go ahead.
+func (t *Tracker) Resume() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.draining = false
+}
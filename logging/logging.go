@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"fmt"
+	"foulbot/config"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+var rotator *rotatingFile
+
+// Setup installs the default slog logger described by cfg, writing to stderr
+// and a rotating file in cfg.LogDir. The standard log package is routed
+// through it too.
+func Setup(cfg *config.Config) error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		return fmt.Errorf("invalid log_level %q", cfg.LogLevel)
+	}
+
+	var err error
+	rotator, err = openRotatingFile(cfg.LogDir, int64(cfg.LogMaxSizeMB)<<20, cfg.LogMaxAgeDays)
+	if err != nil {
+		return err
+	}
+	w := io.MultiWriter(os.Stderr, rotator)
+
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(cfg.LogFormat) {
+	case "json":
+		handler = slog.NewJSONHandler(w, options)
+	case "text", "":
+		handler = slog.NewTextHandler(w, options)
+	default:
+		return fmt.Errorf("invalid log_format %q", cfg.LogFormat)
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// Files returns up to n log files, newest first. It is empty if Setup was
+// never called.
+func Files(n int) []string {
+	if rotator == nil {
+		return nil
+	}
+	return rotator.files(n)
+}
+
+// ForInteraction returns a logger carrying the fields that identify an
+// interaction.
+func ForInteraction(i *discordgo.InteractionCreate) *slog.Logger {
+	logger := slog.With("guild", i.GuildID, "channel", i.ChannelID, "interaction", i.ID)
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand, discordgo.InteractionApplicationCommandAutocomplete:
+		logger = logger.With("command", i.ApplicationCommandData().Name)
+	case discordgo.InteractionMessageComponent:
+		logger = logger.With("component", i.MessageComponentData().CustomID)
+	}
+	if i.Member != nil && i.Member.User != nil {
+		logger = logger.With("user", i.Member.User.ID)
+	}
+	return logger
+}
@@ -0,0 +1,114 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const logName = "foulbot.log"
+
+// rotatingFile appends to dir/foulbot.log, renaming it to
+// foulbot-<timestamp>.log once it would grow past maxSize and deleting rotated
+// files older than maxAgeDays.
+type rotatingFile struct {
+	dir        string
+	maxSize    int64
+	maxAgeDays int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func openRotatingFile(dir string, maxSize int64, maxAgeDays int) (*rotatingFile, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	r := &rotatingFile{dir: dir, maxSize: maxSize, maxAgeDays: maxAgeDays}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	r.prune()
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(filepath.Join(r.dir, logName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.file, r.size = f, info.Size()
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) rotate() error {
+	r.file.Close()
+	rotated := filepath.Join(r.dir, "foulbot-"+time.Now().Format("20060102T150405.000")+".log")
+	if err := os.Rename(filepath.Join(r.dir, logName), rotated); err != nil {
+		return err
+	}
+	r.prune()
+	return r.open()
+}
+
+func (r *rotatingFile) prune() {
+	if r.maxAgeDays <= 0 {
+		return
+	}
+	cutoff := time.Now().AddDate(0, 0, -r.maxAgeDays)
+	for _, path := range r.rotated() {
+		if info, err := os.Stat(path); err == nil && info.ModTime().Before(cutoff) {
+			os.Remove(path)
+		}
+	}
+}
+
+// rotated lists rotated files, newest first.
+func (r *rotatingFile) rotated() []string {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if name != logName && strings.HasPrefix(name, "foulbot-") && strings.HasSuffix(name, ".log") {
+			paths = append(paths, filepath.Join(r.dir, name))
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+	return paths
+}
+
+func (r *rotatingFile) files(n int) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	files := append([]string{filepath.Join(r.dir, logName)}, r.rotated()...)
+	if len(files) > n {
+		files = files[:n]
+	}
+	return files
+}
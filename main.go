@@ -1,14 +1,24 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"foulbot/awards"
+	"foulbot/cli"
 	"foulbot/config"
 	"foulbot/data"
+	"foulbot/discord"
 	"foulbot/inputs"
+	"foulbot/lifecycle"
+	"foulbot/logging"
+	"foulbot/metrics"
+	"foulbot/outbox"
+	"foulbot/updater"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
-	"strings"
+	"runtime/debug"
 	"syscall"
 	"time"
 
@@ -16,28 +26,55 @@ import (
 )
 
 func main() {
-	bot, guildId, appId := loadEnv()
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run(os.Args[1:]))
+	}
+
+	bot, cfg := loadEnv()
+	err := logging.Setup(cfg)
+	if err != nil {
+		log.Fatalf("could not set up logging: %s", err)
+	}
+	data.Open()
 
-	inputs.HandleInputs(bot)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	tracker := &lifecycle.Tracker{}
 
-	err := bot.Open()
+	router := inputs.Default(cfg, tracker)
+	inputs.HandleInputs(ctx, bot, router)
+
+	err = bot.Open()
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer bot.Close()
 
-	handleExpiredPolls(bot)
+	if cfg.MetricsAddr != "" {
+		metrics.Serve(ctx, cfg.MetricsAddr, bot, data.Ping)
+	}
 
-	establishCommands(bot, guildId, appId)
-	fmt.Println("Bot is running...")
+	handleExpiredPolls(ctx, bot, cfg.DiscordGuildID, tracker)
+	handleBackups(ctx, cfg)
+	handleUpdateChecks(ctx, bot, cfg)
+	handleAwards(ctx, bot, cfg, tracker)
 
-	sc := make(chan os.Signal, 1)
-	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
-	<-sc
-	fmt.Println("Bot is shutting down...")
+	establishCommands(bot, cfg.DiscordGuildID, cfg.DiscordAppID, router)
+	metrics.SetReady()
+	err = updater.ReportHealthy()
+	if err != nil {
+		slog.Error("Failed to report healthy after update", "err", err)
+	}
+	slog.Info("Bot is running", "version", config.VERSION)
+
+	<-ctx.Done()
+	slog.Info("Bot is shutting down")
+	if !tracker.Drain(config.SHUTDOWN_TIMEOUT) {
+		slog.Warn("Gave up waiting for in-flight work", "timeout", config.SHUTDOWN_TIMEOUT)
+	}
 }
 
-func loadEnv() (*discordgo.Session, string, string) {
+func loadEnv() (*discordgo.Session, *config.Config) {
 	config, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("could not load config: %s", err)
@@ -47,239 +84,164 @@ func loadEnv() (*discordgo.Session, string, string) {
 	if err != nil {
 		log.Fatal(err)
 	}
+	bot.Client.Transport = metrics.CountingTransport(bot.Client.Transport)
 
-	return bot, config.DiscordGuildID, config.DiscordAppID
+	return bot, config
 }
 
-func handleExpiredPolls(bot *discordgo.Session) {
+func handleBackups(ctx context.Context, cfg *config.Config) {
+	if cfg.BackupIntervalHours <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(cfg.BackupIntervalHours) * time.Hour)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			path, err := data.RotateBackups(cfg.BackupDir, cfg.BackupRetention)
+			if err != nil {
+				slog.Error("Failed to back up database", "err", err)
+				continue
+			}
+			slog.Info("Backed up database", "path", path)
+		}
+	}()
+}
+
+// handleExpiredPolls evaluates expired polls every minute, starting right
+// away so results missed by a previous run are posted on startup.
+func handleExpiredPolls(ctx context.Context, bot discord.Session, guildId string, tracker *lifecycle.Tracker) {
 	ticker := time.NewTicker(1 * time.Minute)
 	go func() {
-		for range ticker.C {
+		defer ticker.Stop()
+		for {
+			if tracker.Start() {
+				runTracked(tracker, "poll evaluation", func() { processExpiredPolls(ctx, bot, guildId) })
+			}
 
-			expiredPolls := data.ExpiredPolls() // returns []Poll
-			for _, poll := range expiredPolls {
-				// Count 👍 reactions
-				upReactions, err := bot.MessageReactions(poll.ChannelId, poll.MessageId, "👍", 100, "", "")
-				if err != nil {
-					log.Printf("Failed to get thumbs up reactions for poll %s: %v", poll.MessageId, err)
-				} else {
-					for _, user := range upReactions {
-						data.Vote(poll.ChannelId, poll.MessageId, user.ID, true)
-					}
-				}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
 
-				// Count 👎 reactions
-				downReactions, err := bot.MessageReactions(poll.ChannelId, poll.MessageId, "👎", 100, "", "")
-				if err != nil {
-					log.Printf("Failed to get thumbs down reactions for poll %s: %v", poll.MessageId, err)
-				} else {
-					for _, user := range downReactions {
-						data.Vote(poll.ChannelId, poll.MessageId, user.ID, false)
-					}
-				}
+func processExpiredPolls(ctx context.Context, bot discord.Session, guildId string) {
+	expiredPolls := data.ExpiredPolls() // returns []Poll
+	for _, poll := range expiredPolls {
+		// Count 👍 reactions
+		upReactions, err := bot.MessageReactions(poll.ChannelId, poll.MessageId, "👍", 100, "", "")
+		if err != nil {
+			slog.Error("Failed to get thumbs up reactions", "channel", poll.ChannelId, "poll", poll.MessageId, "err", err)
+		} else {
+			for _, user := range upReactions {
+				data.Vote(poll.ChannelId, poll.MessageId, user.ID, true)
 			}
+		}
 
-			evaluatedPolls := data.EvaluatePolls()
-			for _, poll := range evaluatedPolls {
-				fields := []*discordgo.MessageEmbedField{
-					{
-						Name:   "Creator",
-						Value:  fmt.Sprintf("<@%s>", poll.CreatorId),
-						Inline: true,
-					},
-					{
-						Name:   "Gainers",
-						Value:  fmt.Sprintf("<@%s>", strings.Join(poll.GainerIds, ">\n<@")),
-						Inline: true,
-					},
-					{
-						Name:   "Points",
-						Value:  fmt.Sprintf("%+d", poll.Points),
-						Inline: true,
-					},
-					{
-						Name:   "Reason",
-						Value:  fmt.Sprintf("[%s](https://discord.com/channels/%s/%s/%s)", poll.Reason, bot.State.Guilds[0].ID, poll.ChannelId, poll.MessageId),
-						Inline: false,
-					},
-					{
-						Name: "Votes For",
-						Value: func() string {
-							if len(poll.VotesFor) == 0 {
-								return "none"
-							}
-							return fmt.Sprintf("<@%s>", strings.Join(poll.VotesFor, ">\n<@"))
-						}(),
-						Inline: true,
-					},
-					{
-						Name: "Votes Against",
-						Value: func() string {
-							if len(poll.VotesAgainst) == 0 {
-								return "none"
-							}
-							return fmt.Sprintf("<@%s>", strings.Join(poll.VotesAgainst, ">\n<@"))
-						}(),
-						Inline: true,
-					},
-				}
-				embed := &discordgo.MessageEmbed{
-					Title:  map[bool]string{true: "Passed", false: "Failed"}[poll.Passed],
-					Color:  0x417e4b, // Green for passed
-					Fields: fields,
-				}
-				if !poll.Passed {
-					embed.Color = 0xc94543 // Red for failed
-				}
+		// Count 👎 reactions
+		downReactions, err := bot.MessageReactions(poll.ChannelId, poll.MessageId, "👎", 100, "", "")
+		if err != nil {
+			slog.Error("Failed to get thumbs down reactions", "channel", poll.ChannelId, "poll", poll.MessageId, "err", err)
+		} else {
+			for _, user := range downReactions {
+				data.Vote(poll.ChannelId, poll.MessageId, user.ID, false)
+			}
+		}
+	}
 
-				message, err := bot.ChannelMessageSendEmbed(poll.ChannelId, embed)
-				if err != nil {
-					log.Printf("Failed to send poll result: %v", err)
-				}
+	start := time.Now()
+	passed, failed := data.EvaluatePolls()
+	metrics.EvaluationSeconds.Observe(time.Since(start).Seconds())
+	metrics.PollsPassed.Add(passed)
+	metrics.PollsFailed.Add(failed)
 
-				bot.MessageThreadStartComplex(message.ChannelID, message.ID, &discordgo.ThreadStart{
-					Name:                "Result",
-					AutoArchiveDuration: 60,
-				})
+	outbox.Process(ctx, bot, guildId)
+	metrics.SchedulerRan()
+}
 
-				bot.ChannelMessageEditComplex(&discordgo.MessageEdit{
-					ID:         poll.MessageId,
-					Channel:    poll.ChannelId,
-					Components: &[]discordgo.MessageComponent{},
-				})
+func handleUpdateChecks(ctx context.Context, bot *discordgo.Session, cfg *config.Config) {
+	if cfg.UpdateNoticeChannelID == "" {
+		return
+	}
+	ticker := time.NewTicker(24 * time.Hour)
+	go func() {
+		defer ticker.Stop()
+		notified := config.VERSION
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
 
+			latest, err := updater.LatestVersion(cfg.ReleasesURL)
+			if err != nil {
+				slog.Warn("Failed to check for updates", "err", err)
+				continue
 			}
+			if updater.CompareVersions(latest, notified) <= 0 {
+				continue
+			}
+			_, err = bot.ChannelMessageSend(cfg.UpdateNoticeChannelID,
+				fmt.Sprintf("Version %s is available (running %s). An admin can install it with `/update install`.", latest, config.VERSION))
+			if err != nil {
+				slog.Error("Failed to send update notice", "channel", cfg.UpdateNoticeChannelID, "err", err)
+				continue
+			}
+			notified = latest
 		}
 	}()
 }
 
-func establishCommands(bot *discordgo.Session, guildId string, appId string) {
-	commands := []*discordgo.ApplicationCommand{
-		{
-			Name:        "own",
-			Description: "Accuse someone of gaining",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionUser,
-					Name:        "user",
-					Description: "The user to mention",
-					Required:    true,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionInteger,
-					Name:        "number",
-					Description: "An integer value",
-					Required:    true,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "reason",
-					Description: "The reason for gaining",
-					Required:    true,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionUser,
-					Name:        "user2",
-					Description: "Additional user to mention (optional)",
-					Required:    false,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionUser,
-					Name:        "user3",
-					Description: "Additional user to mention (optional)",
-					Required:    false,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionUser,
-					Name:        "user4",
-					Description: "Additional user to mention (optional)",
-					Required:    false,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionUser,
-					Name:        "user5",
-					Description: "Additional user to mention (optional)",
-					Required:    false,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionUser,
-					Name:        "user6",
-					Description: "Additional user to mention (optional)",
-					Required:    false,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionUser,
-					Name:        "user7",
-					Description: "Additional user to mention (optional)",
-					Required:    false,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionUser,
-					Name:        "user8",
-					Description: "Additional user to mention (optional)",
-					Required:    false,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionUser,
-					Name:        "user9",
-					Description: "Additional user to mention (optional)",
-					Required:    false,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionUser,
-					Name:        "user10",
-					Description: "Additional user to mention (optional)",
-					Required:    false,
-				},
-			},
-		},
-		{
-			Name:        "leaderboard",
-			Description: fmt.Sprintf("Displays a top %d leaderboard", len(config.NUMBERS)),
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "year",
-					Description: "Year to show leaderboard for (defaults to current year)",
-					Required:    false,
-				},
-			},
-		},
-		{
-			Name:        "version",
-			Description: "Displays the current version",
-			Options:     []*discordgo.ApplicationCommandOption{},
-		},
-		{
-			Name:        "update",
-			Description: "Update the bot to a new version",
-			Options:     []*discordgo.ApplicationCommandOption{},
-		},
-		{
-			Name:        "logs",
-			Description: "Uploads files importing for debugging",
-			Options:     []*discordgo.ApplicationCommandOption{},
-		},
-		{
-			Name:        "status",
-			Description: "Displays how many points a user has",
-			Options: []*discordgo.ApplicationCommandOption{
-				{
-					Type:        discordgo.ApplicationCommandOptionUser,
-					Name:        "user",
-					Description: "The user to check",
-					Required:    true,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "year",
-					Description: "Year to show status for (defaults to current year)",
-					Required:    false,
-				},
-			},
-		},
+// handleAwards holds the year-end ceremony once the year's last polls are
+// evaluated, checking hourly and right away in case it was missed.
+func handleAwards(ctx context.Context, bot discord.Session, cfg *config.Config, tracker *lifecycle.Tracker) {
+	if cfg.AwardsChannelID == "" {
+		return
 	}
-	_, err := bot.ApplicationCommandBulkOverwrite(appId, guildId, commands)
+	ticker := time.NewTicker(time.Hour)
+	go func() {
+		defer ticker.Stop()
+		for {
+			if tracker.Start() {
+				runTracked(tracker, "awards", func() {
+					awards.Run(bot, cfg.DiscordGuildID, cfg.AwardsChannelID, cfg.TrophyRoleID, time.Now())
+				})
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// runTracked runs a unit of scheduled work admitted by tracker.Start,
+// recovering from panics in the data layer so that one bad query doesn't take
+// the bot down.
+func runTracked(tracker *lifecycle.Tracker, name string, work func()) {
+	defer tracker.Done()
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Scheduled work panicked", "work", name, "err", r, "stack", string(debug.Stack()))
+		}
+	}()
+	work()
+}
+
+// establishCommands registers the router's commands with the guild.
+func establishCommands(bot *discordgo.Session, guildId string, appId string, router *inputs.Router) {
+	_, err := bot.ApplicationCommandBulkOverwrite(appId, guildId, router.Definitions())
 	if err != nil {
 		log.Fatalf("could not register commands: %s", err)
 	}
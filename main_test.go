@@ -0,0 +1,801 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"foulbot/awards"
+	"foulbot/config"
+	"foulbot/data"
+	"foulbot/discord/discordtest"
+	"foulbot/inputs"
+	"foulbot/lifecycle"
+	"foulbot/outbox"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	testGuild   = "guild"
+	testChannel = "channel"
+)
+
+func TestMain(m *testing.M) {
+	// Polls expire as soon as they are created.
+	config.POLL_LENGTH = -48 * time.Hour
+	m.Run()
+}
+
+// setup gives each test run its own empty database, fake session and
+// router.
+func setup(t *testing.T) (*discordtest.Fake, *inputs.Router) {
+	data.OpenMemory(fmt.Sprintf("%s_%d", t.Name(), time.Now().UnixNano()))
+	return discordtest.New(), inputs.Default(config.Default(), &lifecycle.Tracker{})
+}
+
+func member(id string) *discordgo.Member {
+	return &discordgo.Member{User: &discordgo.User{ID: id, Username: id}}
+}
+
+func command(name, userId string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:        "interaction-" + name,
+		Type:      discordgo.InteractionApplicationCommand,
+		GuildID:   testGuild,
+		ChannelID: testChannel,
+		Member:    member(userId),
+		Data: discordgo.ApplicationCommandInteractionData{
+			Name:    name,
+			Options: options,
+		},
+	}}
+}
+
+func button(customId, messageId, userId string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:        "interaction-" + customId,
+		Type:      discordgo.InteractionMessageComponent,
+		GuildID:   testGuild,
+		ChannelID: testChannel,
+		Member:    member(userId),
+		Message:   &discordgo.Message{ID: messageId, ChannelID: testChannel},
+		Data:      discordgo.MessageComponentInteractionData{CustomID: customId},
+	}}
+}
+
+func own(gainer string, points int64, reason string) []*discordgo.ApplicationCommandInteractionDataOption {
+	return []*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "user", Type: discordgo.ApplicationCommandOptionUser, Value: gainer},
+		{Name: "number", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(points)},
+		{Name: "reason", Type: discordgo.ApplicationCommandOptionString, Value: reason},
+	}
+}
+
+// lastPoll finds the most recent poll message; creating a poll also tags
+// the gainers in its thread.
+func lastPoll(fake *discordtest.Fake) *discordgo.Message {
+	for i := len(fake.Messages) - 1; i >= 0; i-- {
+		message := fake.Messages[i]
+		if len(message.Embeds) == 1 && message.Embeds[0].Title == "Own" {
+			return message
+		}
+	}
+	return nil
+}
+
+func TestPollLifecycle(t *testing.T) {
+	ctx := context.Background()
+	fake, router := setup(t)
+
+	router.Handle(ctx, fake, command("own", "creator", own("gainer", 3, "spilled the drinks")...))
+	poll := lastPoll(fake)
+	if poll == nil {
+		t.Fatalf("expected a poll message, got %+v", poll)
+	}
+	if len(fake.Threads) != 1 {
+		t.Fatalf("expected a discussion thread for the poll, got %d", len(fake.Threads))
+	}
+
+	router.Handle(ctx, fake, button("vote_yes", poll.ID, "alice"))
+	router.Handle(ctx, fake, button("vote_yes", poll.ID, "bob"))
+	router.Handle(ctx, fake, button("vote_no", poll.ID, "carol"))
+	votes := 0
+	for _, response := range fake.Responses {
+		if strings.HasPrefix(response.Data.Content, "Vote recorded") {
+			votes++
+		}
+	}
+	if votes != 3 {
+		t.Fatalf("expected 3 vote confirmations, got %d", votes)
+	}
+
+	processExpiredPolls(ctx, fake, testGuild)
+	result := fake.LastMessage()
+	if result == nil || len(result.Embeds) != 1 || result.Embeds[0].Title == "Own" {
+		t.Fatalf("expected a result message, got %+v", result)
+	}
+	if !strings.Contains(result.Embeds[0].Title, "Passed") {
+		t.Errorf("expected the poll to pass, got title %q", result.Embeds[0].Title)
+	}
+	if len(fake.Edits) != 1 || fake.Edits[0].ID != poll.ID {
+		t.Errorf("expected the poll buttons to be removed, got %+v", fake.Edits)
+	}
+	if len(fake.Threads) != 2 {
+		t.Errorf("expected a thread on the result, got %d threads", len(fake.Threads))
+	}
+
+	// A second run has nothing left to announce.
+	sent := len(fake.Messages)
+	processExpiredPolls(ctx, fake, testGuild)
+	if len(fake.Messages) != sent {
+		t.Errorf("expected no further messages, got %d", len(fake.Messages)-sent)
+	}
+
+	router.Handle(ctx, fake, command("leaderboard", "creator",
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "period", Type: discordgo.ApplicationCommandOptionString, Value: "all-time"}))
+	leaderboard := fake.LastMessage()
+	if leaderboard == nil || len(leaderboard.Embeds) != 1 {
+		t.Fatalf("expected a leaderboard message, got %+v", leaderboard)
+	}
+	if !strings.Contains(leaderboard.Embeds[0].Description, "<@gainer>: 3") {
+		t.Errorf("expected gainer on the leaderboard, got %q", leaderboard.Embeds[0].Description)
+	}
+	if leaderboard.Embeds[0].Title != "Leaderboard all time" {
+		t.Errorf("expected the period in the title, got %q", leaderboard.Embeds[0].Title)
+	}
+}
+
+func TestFailedPoll(t *testing.T) {
+	ctx := context.Background()
+	fake, router := setup(t)
+
+	router.Handle(ctx, fake, command("own", "creator", own("dave", 5, "nothing really")...))
+	poll := lastPoll(fake)
+	router.Handle(ctx, fake, button("vote_no", poll.ID, "alice"))
+	// Reactions left on the message count as votes too.
+	fake.Reactions[poll.ID+"👎"] = []*discordgo.User{{ID: "bob"}}
+
+	processExpiredPolls(ctx, fake, testGuild)
+	result := fake.LastMessage()
+	if len(result.Embeds) != 1 || !strings.Contains(result.Embeds[0].Title, "Failed") {
+		t.Fatalf("expected a failed result, got %+v", result)
+	}
+	if points := data.Status("dave", time.Time{}, time.Now().AddDate(1, 0, 0)); points != 0 {
+		t.Errorf("expected no points for a failed poll, got %d", points)
+	}
+}
+
+func TestOutboxRetryDoesNotRepost(t *testing.T) {
+	ctx := context.Background()
+	fake, router := setup(t)
+
+	router.Handle(ctx, fake, command("own", "creator", own("gainer", 2, "left early")...))
+	poll := lastPoll(fake)
+	data.EvaluatePolls()
+
+	// An attempt that posted the result but never completed its entry
+	for _, entry := range data.DueOutbox(time.Now()) {
+		data.ClaimOutbox(entry.Id)
+	}
+	fake.ChannelMessageSendEmbed(testChannel, &discordgo.MessageEmbed{
+		Title: "Passed",
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Reason", Value: fmt.Sprintf("[left early](https://discord.com/channels/%s/%s/%s)", testGuild, testChannel, poll.ID)},
+		},
+	})
+
+	sent := len(fake.Messages)
+	outbox.Process(ctx, fake, testGuild)
+	if len(fake.Messages) != sent {
+		t.Errorf("expected the result not to be posted again, got %+v", fake.LastMessage())
+	}
+	if len(fake.Threads) != 2 || fake.Threads[1].Name != "Result" {
+		t.Fatalf("expected a thread on the earlier result, got %+v", fake.Threads)
+	}
+	if len(data.DueOutbox(time.Now())) != 0 {
+		t.Errorf("expected the outbox to be empty, got %+v", data.DueOutbox(time.Now()))
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	fake, router := setup(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "date,gainers,points,reason\n2024-01-01,<@111>,3,late\n2024-12-31,222,2,argued\n")
+	}))
+	defer server.Close()
+	fake.Members["111"] = true
+
+	importFile := func(dryRun bool) string {
+		i := command("import", "admin",
+			&discordgo.ApplicationCommandInteractionDataOption{Name: "file", Type: discordgo.ApplicationCommandOptionAttachment, Value: "file"},
+			&discordgo.ApplicationCommandInteractionDataOption{Name: "dry_run", Type: discordgo.ApplicationCommandOptionBoolean, Value: dryRun})
+		i.Member.Permissions = discordgo.PermissionAdministrator
+		data := i.Data.(discordgo.ApplicationCommandInteractionData)
+		data.Resolved = &discordgo.ApplicationCommandInteractionDataResolved{Attachments: map[string]*discordgo.MessageAttachment{
+			"file": {URL: server.URL, Filename: "history.csv"},
+		}}
+		i.Data = data
+		router.Handle(context.Background(), fake, i)
+		return fake.Followups[len(fake.Followups)-1].Content
+	}
+
+	// Gainers are checked against the guild before anything else
+	if got := importFile(true); got != "Import failed, not guild members: <@222>" {
+		t.Errorf("expected 222 to be rejected, got %q", got)
+	}
+	fake.Members["222"] = true
+	if got := importFile(true); !strings.HasPrefix(got, "Dry run, nothing was imported:\n2 polls\n2024: +5 points\n") {
+		t.Errorf("expected a preview, got %q", got)
+	}
+	if polls, total := data.History(data.HistoryFilter{To: time.Now()}, 0, 10); total != 0 {
+		t.Errorf("expected a dry run to import nothing, got %+v", polls)
+	}
+	if got := importFile(false); !strings.HasPrefix(got, "Imported 2 new polls (0 already present)") {
+		t.Errorf("expected the polls to be imported, got %q", got)
+	}
+}
+
+func TestOwnRejectsThreads(t *testing.T) {
+	fake, router := setup(t)
+	fake.Channels[testChannel] = &discordgo.Channel{ID: testChannel, Type: discordgo.ChannelTypeGuildPublicThread}
+
+	router.Handle(context.Background(), fake, command("own", "creator", own("gainer", 1, "in a thread")...))
+	if len(fake.Messages) != 0 {
+		t.Fatalf("expected no poll in a thread, got %d messages", len(fake.Messages))
+	}
+	if len(fake.Responses) != 1 || !strings.Contains(fake.Responses[0].Data.Content, "threads") {
+		t.Errorf("expected an ephemeral rejection, got %+v", fake.Responses)
+	}
+}
+
+func TestPrivilegedCommandDenied(t *testing.T) {
+	fake, router := setup(t)
+
+	router.Handle(context.Background(), fake, command("outbox", "someone",
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "dead", Type: discordgo.ApplicationCommandOptionSubCommand}))
+	if len(fake.Responses) != 1 || !strings.Contains(fake.Responses[0].Data.Content, "permission") {
+		t.Fatalf("expected a permission denial, got %+v", fake.Responses)
+	}
+}
+
+func TestDefinitions(t *testing.T) {
+	_, router := setup(t)
+
+	privileged := map[string]bool{}
+	for _, definition := range router.Definitions() {
+		privileged[definition.Name] = definition.DefaultMemberPermissions != nil
+	}
+	for _, name := range []string{"update", "logs", "restore", "import", "export", "outbox", "season"} {
+		if !privileged[name] {
+			t.Errorf("expected /%s to be limited to admins", name)
+		}
+	}
+	if privileged["own"] || privileged["leaderboard"] || privileged["seasons"] {
+		t.Errorf("expected /own, /leaderboard and /seasons to be open to everyone")
+	}
+}
+
+func TestPanicIsReported(t *testing.T) {
+	fake, _ := setup(t)
+	cfg := config.Default()
+	cfg.ErrorChannelID = "errors"
+	router := inputs.Default(cfg, &lifecycle.Tracker{})
+
+	// /status without its required user option panics in the handler.
+	router.Handle(context.Background(), fake, command("status", "someone"))
+	if len(fake.Responses) != 1 || !strings.Contains(fake.Responses[0].Data.Content, "error id") {
+		t.Fatalf("expected an error id for the user, got %+v", fake.Responses)
+	}
+	content := fake.Responses[0].Data.Content
+	id := strings.Trim(content[strings.LastIndex(content, " ")+1:], "`")
+
+	report := fake.LastMessage()
+	if report == nil || report.ChannelID != "errors" || !strings.Contains(report.Content, id) {
+		t.Fatalf("expected error %s in the error channel, got %+v", id, report)
+	}
+}
+
+func TestLeaderboardPages(t *testing.T) {
+	fake, router := setup(t)
+	ctx := context.Background()
+
+	// user0 and user1 tie for first, everyone else trails
+	var polls []data.ImportedPoll
+	for n := 0; n < 13; n++ {
+		points := int64(20 - n)
+		if n == 1 {
+			points = 20
+		}
+		polls = append(polls, data.ImportedPoll{
+			Date:      time.Now().Add(-time.Hour),
+			GainerIds: []string{fmt.Sprintf("user%d", n)},
+			Points:    points,
+			Reason:    "seeded",
+			Passed:    true,
+		})
+	}
+	_, err := data.ImportPolls("creator", polls)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.Handle(ctx, fake, command("leaderboard", "user12"))
+	if len(fake.Responses) != 1 || fake.Responses[0].Data.Content != fmt.Sprintf("Your rank is #13 of 13 for %d, with 8 points.", time.Now().Year()) {
+		t.Fatalf("expected the user's rank, got %+v", fake.Responses)
+	}
+	leaderboard := fake.Messages[0]
+	description := leaderboard.Embeds[0].Description
+	if !strings.Contains(description, ":one: <@user0>: 20") || !strings.Contains(description, ":one: <@user1>: 20") ||
+		!strings.Contains(description, ":three: <@user2>: 18") || strings.Contains(description, "user11") {
+		t.Errorf("expected tied first places on page one, got %q", description)
+	}
+
+	buttons := leaderboard.Components[0].(discordgo.ActionsRow).Components
+	previous, next, me := buttons[0].(discordgo.Button), buttons[1].(discordgo.Button), buttons[2].(discordgo.Button)
+	if !previous.Disabled || next.Disabled {
+		t.Errorf("expected only next to be enabled on page one")
+	}
+
+	router.Handle(ctx, fake, button(next.CustomID, leaderboard.ID, "user0"))
+	page := fake.Responses[1].Data
+	if fake.Responses[1].Type != discordgo.InteractionResponseUpdateMessage ||
+		!strings.Contains(page.Embeds[0].Description, "**#11** <@user10>: 10") ||
+		page.Embeds[0].Footer.Text != "Page 2 of 2" {
+		t.Errorf("expected page two, got %+v", page.Embeds[0])
+	}
+
+	router.Handle(ctx, fake, button(me.CustomID, leaderboard.ID, "user3"))
+	if page := fake.Responses[2].Data; page.Embeds[0].Footer.Text != "Page 1 of 2" {
+		t.Errorf("expected to jump back to page one, got %q", page.Embeds[0].Footer.Text)
+	}
+	router.Handle(ctx, fake, button(me.CustomID, leaderboard.ID, "nobody"))
+	if content := fake.Responses[3].Data.Content; !strings.Contains(content, "no points") {
+		t.Errorf("expected an ephemeral note for users without points, got %q", content)
+	}
+}
+
+func TestLeaderboardTypes(t *testing.T) {
+	fake, router := setup(t)
+	ctx := context.Background()
+	defer func(sample int) { config.RATE_MIN_SAMPLE = sample }(config.RATE_MIN_SAMPLE)
+	config.RATE_MIN_SAMPLE = 1
+
+	for _, poll := range []struct {
+		creator string
+		votes   map[string]string
+	}{
+		{"c1", map[string]string{"alice": "vote_yes", "bob": "vote_yes", "carol": "vote_no"}}, // passes
+		{"c1", map[string]string{"alice": "vote_no", "bob": "vote_no", "carol": "vote_yes"}},  // fails
+		{"c2", map[string]string{"alice": "vote_yes", "bob": "vote_no", "carol": "vote_yes"}}, // passes
+	} {
+		router.Handle(ctx, fake, command("own", poll.creator, own("gainer", 1, "seeded")...))
+		message := lastPoll(fake)
+		for voter, vote := range poll.votes {
+			router.Handle(ctx, fake, button(vote, message.ID, voter))
+		}
+	}
+	processExpiredPolls(ctx, fake, testGuild)
+
+	for board, want := range map[string][]string{
+		data.BoardCreators:   {":one: <@c1>: 2 polls", ":two: <@c2>: 1 poll\n"},
+		data.BoardPassRate:   {":one: <@c2>: 100% (1 of 1 passed)", ":two: <@c1>: 50% (1 of 2 passed)"},
+		data.BoardVotes:      {":one: <@alice>: 3 votes", ":one: <@bob>: 3 votes", ":one: <@carol>: 3 votes"},
+		data.BoardContrarian: {":one: <@carol>: 66% (2 of 3", ":two: <@bob>: 33% (1 of 3", ":three: <@alice>: 0% (0 of 3"},
+		data.BoardLenient:    {":one: <@alice>: 66% (2 of 3", ":one: <@carol>: 66% (2 of 3", ":three: <@bob>: 33% (1 of 3"},
+	} {
+		router.Handle(ctx, fake, command("leaderboard", "alice",
+			&discordgo.ApplicationCommandInteractionDataOption{Name: "type", Type: discordgo.ApplicationCommandOptionString, Value: board},
+			&discordgo.ApplicationCommandInteractionDataOption{Name: "period", Type: discordgo.ApplicationCommandOptionString, Value: "all-time"}))
+		description := fake.LastMessage().Embeds[0].Description
+		for _, line := range want {
+			if !strings.Contains(description, line) {
+				t.Errorf("%s: expected %q in %q", board, line, description)
+			}
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	fake, router := setup(t)
+	ctx := context.Background()
+
+	day := time.Date(time.Now().Year(), 1, 10, 12, 0, 0, 0, time.Local)
+	_, err := data.ImportPolls("creator", []data.ImportedPoll{
+		{Date: day, GainerIds: []string{"dave", "erin"}, Points: 5, Reason: "late again", Passed: true},
+		{Date: day.Add(time.Hour), GainerIds: []string{"dave"}, Points: 9, Reason: "Late Again ", Passed: true},
+		{Date: day.Add(2 * time.Hour), GainerIds: []string{"dave", "erin"}, Points: 2, Reason: "forgot the ball", Passed: false},
+		{Date: day.Add(2 * time.Hour), GainerIds: []string{"erin"}, Points: 20, Reason: "unrelated", Passed: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.Handle(ctx, fake, command("status", "someone",
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "user", Type: discordgo.ApplicationCommandOptionUser, Value: "dave"}))
+	if len(fake.Responses) != 1 {
+		t.Fatalf("expected a status response, got %+v", fake.Responses)
+	}
+	status := fake.Responses[0].Data
+	fields := map[string]string{}
+	for _, field := range status.Embeds[0].Fields {
+		fields[field.Name] = field.Value
+	}
+	for name, want := range map[string]string{
+		"Points":                "14",
+		"Rank":                  "#2 of 2",
+		"Polls":                 "2 passed, 1 failed, 0 open",
+		"Biggest foul":          "+9 Late Again  (imported)",
+		"Most often owned with": "<@erin> (2 polls)",
+	} {
+		if fields[name] != want {
+			t.Errorf("expected %s to be %q, got %q", name, want, fields[name])
+		}
+	}
+	if status.Embeds[0].Footer.Text != "Page 1 of 3" {
+		t.Errorf("expected three pages, got %q", status.Embeds[0].Footer.Text)
+	}
+
+	next := status.Components[0].(discordgo.ActionsRow).Components[1].(discordgo.Button)
+	router.Handle(ctx, fake, button(next.CustomID, "status", "someone"))
+	breakdown := fake.Responses[1].Data.Embeds[0]
+	if !strings.Contains(breakdown.Fields[0].Value, "late again: 2 polls, 14 points") {
+		t.Errorf("expected reasons grouped regardless of case, got %q", breakdown.Fields[0].Value)
+	}
+	if !strings.Contains(breakdown.Fields[1].Value, day.Format("2006-01")+": 14") {
+		t.Errorf("expected points by month, got %q", breakdown.Fields[1].Value)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	fake, router := setup(t)
+	ctx := context.Background()
+
+	day := time.Date(2023, 3, 1, 12, 0, 0, 0, time.Local)
+	var polls []data.ImportedPoll
+	for n := 0; n < 12; n++ {
+		polls = append(polls, data.ImportedPoll{Date: day.AddDate(0, 0, n), GainerIds: []string{"dave"}, Points: 1, Reason: fmt.Sprintf("late %d", n), Passed: n != 0})
+	}
+	polls = append(polls,
+		data.ImportedPoll{Date: day, GainerIds: []string{"erin"}, Points: 3, Reason: "late", Passed: true},
+		data.ImportedPoll{Date: day, GainerIds: []string{"dave"}, Points: 5, Reason: "100% wrong", Passed: true})
+	_, err := data.ImportPolls("creator", polls)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.Handle(ctx, fake, command("history", "someone",
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "user", Type: discordgo.ApplicationCommandOptionUser, Value: "dave"},
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "text", Type: discordgo.ApplicationCommandOptionString, Value: "LATE"}))
+	first := fake.Responses[0].Data
+	if first.Embeds[0].Footer.Text != "12 polls, page 1 of 2" {
+		t.Errorf("expected 12 polls on two pages, got %q", first.Embeds[0].Footer.Text)
+	}
+	if !strings.Contains(first.Embeds[0].Description, "`2023-03-12` **+1** <@dave> late 11 (imported), passed") {
+		t.Errorf("expected the newest poll first, got %q", first.Embeds[0].Description)
+	}
+
+	next := first.Components[0].(discordgo.ActionsRow).Components[1].(discordgo.Button)
+	router.Handle(ctx, fake, button(next.CustomID, "history", "someone"))
+	second := fake.Responses[1]
+	if second.Type != discordgo.InteractionResponseUpdateMessage || strings.Count(second.Data.Embeds[0].Description, "\n") != 4 {
+		t.Errorf("expected the last two polls on page 2, got %+v", second.Data.Embeds[0])
+	}
+	if !strings.Contains(second.Data.Embeds[0].Description, "late 0 (imported), failed") {
+		t.Errorf("expected the failed poll on page 2, got %q", second.Data.Embeds[0].Description)
+	}
+
+	router.Handle(ctx, fake, command("history", "someone",
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "text", Type: discordgo.ApplicationCommandOptionString, Value: "0%"}))
+	if footer := fake.Responses[2].Data.Embeds[0].Footer.Text; footer != "1 poll, page 1 of 1" {
+		t.Errorf("expected %% to match literally, got %q", footer)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	fake, router := setup(t)
+	ctx := context.Background()
+
+	day := time.Date(2023, 3, 1, 12, 0, 0, 0, time.Local)
+	_, err := data.ImportPolls("creator", []data.ImportedPoll{
+		{Date: day, GainerIds: []string{"dave"}, Points: 2, Reason: "Running late to practice", Passed: true},
+		{Date: day, GainerIds: []string{"dave"}, Points: 1, Reason: "forgot the ball", Passed: false},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.Handle(ctx, fake, command("own", "creator", own("erin", 3, "late again, ran out of excuses")...))
+	poll := lastPoll(fake)
+
+	search := func(query string) *discordgo.MessageEmbed {
+		router.Handle(ctx, fake, command("search", "someone",
+			&discordgo.ApplicationCommandInteractionDataOption{Name: "query", Type: discordgo.ApplicationCommandOptionString, Value: query}))
+		return fake.Responses[len(fake.Responses)-1].Data.Embeds[0]
+	}
+
+	late := search("late")
+	if late.Footer.Text != "2 polls, page 1 of 1" {
+		t.Errorf("expected two polls mentioning late, got %q", late.Footer.Text)
+	}
+	if !strings.Contains(late.Description, "Running **late** to practice, passed (imported)") {
+		t.Errorf("expected the match highlighted, got %q", late.Description)
+	}
+	link := fmt.Sprintf("[jump](https://discord.com/channels/%s/%s/%s)", testGuild, testChannel, poll.ID)
+	if !strings.Contains(late.Description, "**late** again, ran out of excuses, pending "+link) {
+		t.Errorf("expected a jump link to the new poll, got %q", late.Description)
+	}
+
+	if run := search("run"); run.Footer.Text != "1 poll, page 1 of 1" {
+		t.Errorf("expected run to match running, got %q", run.Footer.Text)
+	}
+	if quoted := search(`"ball OR`); quoted.Footer.Text != "0 polls, page 1 of 1" {
+		t.Errorf("expected query syntax to be taken literally, got %q", quoted.Footer.Text)
+	}
+}
+
+func TestVersus(t *testing.T) {
+	fake, router := setup(t)
+	ctx := context.Background()
+	fake.Members = map[string]bool{"dave": true, "erin": true}
+
+	day := time.Date(2023, 3, 1, 12, 0, 0, 0, time.Local)
+	_, err := data.ImportPolls("creator", []data.ImportedPoll{
+		{Date: day, GainerIds: []string{"dave"}, Points: 5, Reason: "late", Passed: true},
+		{Date: day.AddDate(0, 2, 0), GainerIds: []string{"dave", "erin"}, Points: 2, Reason: "both late", Passed: true},
+		{Date: day.AddDate(0, 3, 0), GainerIds: []string{"erin"}, Points: 4, Reason: "forgot", Passed: false},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.Handle(ctx, fake, command("own", "dave", own("erin", 3, "spilled the drinks")...))
+	router.Handle(ctx, fake, button("vote_yes", lastPoll(fake).ID, "dave"))
+
+	router.Handle(ctx, fake, command("versus", "someone",
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "user1", Type: discordgo.ApplicationCommandOptionUser, Value: "erin"},
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "user2", Type: discordgo.ApplicationCommandOptionUser, Value: "dave"},
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "period", Type: discordgo.ApplicationCommandOptionString, Value: "all-time"}))
+	response := fake.Responses[len(fake.Responses)-1].Data
+	embed := response.Embeds[0]
+	if embed.Description != "<@dave> is ahead of <@erin> by 5 points." {
+		t.Errorf("expected dave ahead, got %q", embed.Description)
+	}
+	if !strings.Contains(embed.Fields[0].Value, "**Polls** 1 passed, 1 failed, 1 open") {
+		t.Errorf("expected erin's polls, got %q", embed.Fields[0].Value)
+	}
+	if !strings.Contains(embed.Fields[1].Value, "**On <@erin>'s 3 polls** created 1, voted 👍 1 👎 0") {
+		t.Errorf("expected dave's votes on erin's polls, got %q", embed.Fields[1].Value)
+	}
+
+	if len(response.Files) != 1 {
+		t.Fatalf("expected a chart attachment, got %d files", len(response.Files))
+	}
+	chart, err := png.Decode(response.Files[0].Reader)
+	if err != nil {
+		t.Fatalf("expected a PNG chart: %v", err)
+	}
+	if chart.Bounds().Dx() != 800 {
+		t.Errorf("expected an 800 pixel wide chart, got %v", chart.Bounds())
+	}
+}
+
+func TestCharts(t *testing.T) {
+	fake, router := setup(t)
+	ctx := context.Background()
+
+	day := time.Date(2023, 3, 1, 12, 0, 0, 0, time.Local)
+	_, err := data.ImportPolls("creator", []data.ImportedPoll{
+		{Date: day, GainerIds: []string{"dave"}, Points: 5, Reason: "late", Passed: true},
+		{Date: day.AddDate(0, 2, 0), GainerIds: []string{"dave", "erin"}, Points: 2, Reason: "both late", Passed: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	period := &discordgo.ApplicationCommandInteractionDataOption{Name: "when", Type: discordgo.ApplicationCommandOptionString, Value: "2023"}
+
+	for _, kind := range []string{"bars", "race"} {
+		router.Handle(ctx, fake, command("leaderboard", "dave", period,
+			&discordgo.ApplicationCommandInteractionDataOption{Name: "chart", Type: discordgo.ApplicationCommandOptionString, Value: kind}))
+		leaderboard := fake.Messages[len(fake.Messages)-1]
+		if len(leaderboard.Attachments) != 1 || leaderboard.Embeds[0].Image == nil {
+			t.Errorf("expected a %s chart on the leaderboard, got %+v", kind, leaderboard)
+		}
+	}
+
+	router.Handle(ctx, fake, command("status", "dave",
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "user", Type: discordgo.ApplicationCommandOptionUser, Value: "dave"}, period,
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "chart", Type: discordgo.ApplicationCommandOptionBoolean, Value: true}))
+	status := fake.Responses[len(fake.Responses)-1].Data
+	if len(status.Files) != 1 || status.Embeds[0].Image.URL != "attachment://status.png" {
+		t.Fatalf("expected a chart on the status, got %+v", status)
+	}
+	if _, err := png.Decode(status.Files[0].Reader); err != nil {
+		t.Errorf("expected a PNG chart: %v", err)
+	}
+}
+
+func TestAwards(t *testing.T) {
+	fake, _ := setup(t)
+
+	day := time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)
+	_, err := data.ImportPolls("creator", []data.ImportedPoll{
+		{Date: day, GainerIds: []string{"dave"}, Points: 4, Reason: "late", Passed: true},
+		{Date: day, GainerIds: []string{"dave", "erin"}, Points: 6, Reason: "argued with the ref", Passed: true},
+		{Date: day, GainerIds: []string{"frank"}, Points: 3, Reason: "forgot the ball", Passed: true},
+		{Date: day.AddDate(1, 0, 0), GainerIds: []string{"erin"}, Points: 2, Reason: "late", Passed: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	argued, _ := data.History(data.HistoryFilter{From: day.AddDate(-1, 0, 0), To: day.AddDate(1, 0, 0), Text: "argued"}, 0, 1)
+	for voter, value := range map[string]bool{"a": true, "b": true, "c": false} {
+		data.Vote(argued[0].ChannelId, argued[0].MessageId, voter, value)
+	}
+	for _, id := range []string{"poll-1", "poll-2"} {
+		data.CreatePoll(data.Poll{ChannelId: testChannel, MessageId: id, CreatorId: "grace", Points: 1, Reason: "offside",
+			GainerIds: []string{"frank"}, Expiry: day.Format(time.RFC3339)})
+	}
+	data.EvaluatePolls()
+
+	awards.Run(fake, testGuild, "awards", "trophy", time.Date(2025, 1, 1, 1, 0, 0, 0, time.Local))
+	if len(fake.Messages) != 1 {
+		t.Fatalf("expected a ceremony, got %d messages", len(fake.Messages))
+	}
+	ceremony := fake.Messages[0].Embeds[0]
+	if ceremony.Title != "🏆 Foul Sport of 2024" || ceremony.Description != "<@dave> is the foul sport of 2024 with 10 points!" {
+		t.Errorf("expected dave to win 2024, got %q: %q", ceremony.Title, ceremony.Description)
+	}
+	fields := map[string]string{}
+	for _, field := range ceremony.Fields {
+		fields[field.Name] = field.Value
+	}
+	for name, want := range map[string]string{
+		"Runners-up":          "🥈 <@erin>: 6 points\n🥉 <@frank>: 3 points",
+		"Most polls created":  "<@grace> with 2",
+		"Biggest single foul": "+6 to <@dave> and <@erin> for argued with the ref",
+		"Most contested poll": "argued with the ref, passed 👍 2 👎 1",
+	} {
+		if fields[name] != want {
+			t.Errorf("expected %s to be %q, got %q", name, want, fields[name])
+		}
+	}
+	if !slices.Equal(fake.Roles["dave"], []string{"trophy"}) {
+		t.Errorf("expected dave to get the trophy role, got %v", fake.Roles)
+	}
+
+	// Running again later changes nothing, and the archive is final
+	awards.Run(fake, testGuild, "awards", "trophy", time.Date(2025, 1, 1, 2, 0, 0, 0, time.Local))
+	if len(fake.Messages) != 1 {
+		t.Errorf("expected one ceremony per season, got %d messages", len(fake.Messages))
+	}
+	if data.ArchiveSeason("2024", day, day) {
+		t.Error("expected the archived season not to be archived again")
+	}
+
+	awards.Run(fake, testGuild, "awards", "trophy", time.Date(2026, 1, 1, 1, 0, 0, 0, time.Local))
+	if len(fake.Roles["dave"]) != 0 || !slices.Equal(fake.Roles["erin"], []string{"trophy"}) {
+		t.Errorf("expected the trophy to move from dave to erin, got %v", fake.Roles)
+	}
+
+	// A year that ended long before awards ran is left alone
+	sent := len(fake.Messages)
+	awards.Run(fake, testGuild, "awards", "trophy", time.Date(2027, 6, 1, 1, 0, 0, 0, time.Local))
+	if len(fake.Messages) != sent {
+		t.Errorf("expected no ceremony for a long finished year, got %+v", fake.LastMessage().Embeds[0].Title)
+	}
+}
+
+func TestSeasons(t *testing.T) {
+	fake, router := setup(t)
+	ctx := context.Background()
+	season := func(subcommand string, options ...*discordgo.ApplicationCommandInteractionDataOption) string {
+		i := command("season", "admin", &discordgo.ApplicationCommandInteractionDataOption{
+			Name: subcommand, Type: discordgo.ApplicationCommandOptionSubCommand, Options: options})
+		i.Member.Permissions = discordgo.PermissionAdministrator
+		router.Handle(ctx, fake, i)
+		data := fake.Responses[len(fake.Responses)-1].Data
+		if len(data.Embeds) > 0 {
+			return data.Embeds[0].Description
+		}
+		return data.Content
+	}
+	name := func(name string) *discordgo.ApplicationCommandInteractionDataOption {
+		return &discordgo.ApplicationCommandInteractionDataOption{Name: "name", Type: discordgo.ApplicationCommandOptionString, Value: name}
+	}
+	date := func(date string) *discordgo.ApplicationCommandInteractionDataOption {
+		return &discordgo.ApplicationCommandInteractionDataOption{Name: "date", Type: discordgo.ApplicationCommandOptionString, Value: date}
+	}
+
+	for _, step := range []struct{ got, want string }{
+		{season("start", name("Spring"), date("2023-03-01")), "Season Spring starts on Mar 1 2023."},
+		{season("start", name("Summer"), date("2023-06-01")), "Season Summer starts on Jun 1 2023, ending Spring."},
+		{season("start", name("Spring"), date("2023-07-01")), "There is already a season called Spring."},
+		{season("start", name("Earlier"), date("2023-05-01")), "Summer started on Jun 1 2023, so a new season has to start after that."},
+		{season("end", date("2023-09-01")), "Season Summer ends on Sep 1 2023. Its awards are handed out once its last polls close."},
+		{season("end", date("2023-10-01")), "No season is running then."},
+	} {
+		if step.got != step.want {
+			t.Errorf("expected %q, got %q", step.want, step.got)
+		}
+	}
+
+	_, err := data.ImportPolls("creator", []data.ImportedPoll{
+		{Date: time.Date(2023, 4, 10, 12, 0, 0, 0, time.Local), GainerIds: []string{"dave"}, Points: 5, Reason: "late", Passed: true},
+		{Date: time.Date(2023, 7, 10, 12, 0, 0, 0, time.Local), GainerIds: []string{"erin"}, Points: 3, Reason: "late", Passed: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router.Handle(ctx, fake, command("leaderboard", "dave",
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "period", Type: discordgo.ApplicationCommandOptionString, Value: "season"},
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "when", Type: discordgo.ApplicationCommandOptionString, Value: "Spring"}))
+	leaderboard := fake.LastMessage()
+	if leaderboard.Embeds[0].Title != "Leaderboard Spring" || !strings.Contains(leaderboard.Embeds[0].Description, "<@dave>: 5") ||
+		strings.Contains(leaderboard.Embeds[0].Description, "erin") {
+		t.Errorf("expected only Spring's polls, got %+v", leaderboard.Embeds[0])
+	}
+	me := leaderboard.Components[0].(discordgo.ActionsRow).Components[2].(discordgo.Button)
+	router.Handle(ctx, fake, button(me.CustomID, leaderboard.ID, "dave"))
+	if title := fake.Responses[len(fake.Responses)-1].Data.Embeds[0].Title; title != "Leaderboard Spring" {
+		t.Errorf("expected the season to survive a page turn, got %q", title)
+	}
+
+	// A season's name is enough on its own, as autocomplete suggests it
+	router.Handle(ctx, fake, command("leaderboard", "erin",
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "when", Type: discordgo.ApplicationCommandOptionString, Value: "Summer"}))
+	if title := fake.LastMessage().Embeds[0].Title; title != "Leaderboard Summer" {
+		t.Errorf("expected the season named in when, got %q", title)
+	}
+
+	// Without a period, status follows the current season
+	season("start", name("Autumn"))
+	router.Handle(ctx, fake, command("status", "erin",
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "user", Type: discordgo.ApplicationCommandOptionUser, Value: "erin"}))
+	if title := fake.Responses[len(fake.Responses)-1].Data.Embeds[0].Title; title != "Status Autumn" {
+		t.Errorf("expected the current season by default, got %q", title)
+	}
+
+	awards.Run(fake, testGuild, "awards", "", time.Now())
+	var ceremonies []string
+	for _, message := range fake.Messages {
+		if message.ChannelID == "awards" {
+			ceremonies = append(ceremonies, message.Embeds[0].Title)
+		}
+	}
+	if !slices.Contains(ceremonies, "🏆 Foul Sport of Spring") || !slices.Contains(ceremonies, "🏆 Foul Sport of Summer") ||
+		slices.Contains(ceremonies, "🏆 Foul Sport of Autumn") {
+		t.Errorf("expected ceremonies for the ended seasons, got %v", ceremonies)
+	}
+
+	// A taken name leaves the running season alone
+	autumn, _ := data.LatestSeason()
+	if data.StartSeason("Spring", time.Now().AddDate(0, 0, 1), data.OpenEnded, autumn.Id) {
+		t.Error("expected a taken name to be refused")
+	}
+	if current, _ := data.LatestSeason(); current.Name != "Autumn" || !current.To.Equal(data.OpenEnded) {
+		t.Errorf("expected Autumn to keep running, got %+v", current)
+	}
+
+	// Anyone can list the seasons
+	router.Handle(ctx, fake, command("seasons", "erin"))
+	list := fake.Responses[len(fake.Responses)-1].Data.Embeds[0].Description
+	for _, want := range []string{"**Spring** Mar 1 2023 to Jun 1 2023, archived, won by <@dave>", "**Autumn** from ", ", current"} {
+		if !strings.Contains(list, want) {
+			t.Errorf("expected %q in the season list, got %q", want, list)
+		}
+	}
+
+	autocomplete := command("leaderboard", "dave",
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "when", Type: discordgo.ApplicationCommandOptionString, Value: "spr", Focused: true})
+	autocomplete.Type = discordgo.InteractionApplicationCommandAutocomplete
+	router.Handle(ctx, fake, autocomplete)
+	choices := fake.Responses[len(fake.Responses)-1].Data.Choices
+	if len(choices) != 2 || choices[1].Value != "Spring" {
+		t.Errorf("expected Spring to be suggested, got %+v", choices)
+	}
+}
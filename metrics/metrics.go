@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+type Counter struct {
+	value atomic.Int64
+}
+
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+func (c *Counter) Add(n int) {
+	c.value.Add(int64(n))
+}
+
+// CounterVec is a counter partitioned by a single label.
+type CounterVec struct {
+	label  string
+	mu     sync.Mutex
+	values map[string]int64
+}
+
+func (c *CounterVec) Inc(value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.values == nil {
+		c.values = make(map[string]int64)
+	}
+	c.values[value]++
+}
+
+type Histogram struct {
+	buckets []float64
+	mu      sync.Mutex
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func (h *Histogram) Observe(value float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.counts == nil {
+		h.counts = make([]uint64, len(h.buckets))
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+var (
+	PollsCreated      = &Counter{}
+	PollsPassed       = &Counter{}
+	PollsFailed       = &Counter{}
+	VotesCast         = &Counter{}
+	DiscordAPIErrors  = &Counter{}
+	Commands          = &CounterVec{label: "command"}
+	EvaluationSeconds = &Histogram{buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}}
+)
+
+// WriteText writes every metric in the Prometheus text exposition format.
+func WriteText(w io.Writer) {
+	writeCounter(w, "foulbot_polls_created_total", "Polls created with /own.", PollsCreated)
+	writeCounter(w, "foulbot_polls_passed_total", "Polls that closed with more votes for than against.", PollsPassed)
+	writeCounter(w, "foulbot_polls_failed_total", "Polls that closed without passing.", PollsFailed)
+	writeCounter(w, "foulbot_votes_cast_total", "Votes cast with the poll buttons.", VotesCast)
+	writeCounter(w, "foulbot_discord_api_errors_total", "Discord REST requests that failed or returned an error status.", DiscordAPIErrors)
+
+	fmt.Fprintln(w, "# HELP foulbot_command_invocations_total Slash commands invoked, by name.")
+	fmt.Fprintln(w, "# TYPE foulbot_command_invocations_total counter")
+	Commands.mu.Lock()
+	names := make([]string, 0, len(Commands.values))
+	for name := range Commands.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "foulbot_command_invocations_total{%s=%q} %d\n", Commands.label, name, Commands.values[name])
+	}
+	Commands.mu.Unlock()
+
+	h := EvaluationSeconds
+	fmt.Fprintln(w, "# HELP foulbot_poll_evaluation_seconds Time taken to evaluate expired polls.")
+	fmt.Fprintln(w, "# TYPE foulbot_poll_evaluation_seconds histogram")
+	h.mu.Lock()
+	for i, bound := range h.buckets {
+		var count uint64
+		if h.counts != nil {
+			count = h.counts[i]
+		}
+		fmt.Fprintf(w, "foulbot_poll_evaluation_seconds_bucket{le=\"%g\"} %d\n", bound, count)
+	}
+	fmt.Fprintf(w, "foulbot_poll_evaluation_seconds_bucket{le=\"+Inf\"} %d\n", h.count)
+	fmt.Fprintf(w, "foulbot_poll_evaluation_seconds_sum %g\n", h.sum)
+	fmt.Fprintf(w, "foulbot_poll_evaluation_seconds_count %d\n", h.count)
+	h.mu.Unlock()
+}
+
+func writeCounter(w io.Writer, name string, help string, c *Counter) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, c.value.Load())
+}
+
+// CountingTransport wraps an http.RoundTripper, counting transport failures
+// and error responses in DiscordAPIErrors.
+func CountingTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return countingTransport{next}
+}
+
+type countingTransport struct {
+	next http.RoundTripper
+}
+
+func (t countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode >= 400 {
+		DiscordAPIErrors.Inc()
+	}
+	return resp, err
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// MAX_SCHEDULER_LAG is how long the poll scheduler may go without a run
+// before /healthz reports it as stuck.
+var MAX_SCHEDULER_LAG = 3 * time.Minute
+
+var (
+	lastSchedulerRun atomic.Int64
+	ready            atomic.Bool
+)
+
+// SchedulerRan records that expired polls were just processed.
+func SchedulerRan() {
+	lastSchedulerRun.Store(time.Now().UnixNano())
+}
+
+// SetReady marks the bot as ready to serve commands.
+func SetReady() {
+	ready.Store(true)
+}
+
+type health struct {
+	Gateway      bool   `json:"gateway_connected"`
+	Database     bool   `json:"database_reachable"`
+	SchedulerLag string `json:"scheduler_lag"`
+	Healthy      bool   `json:"healthy"`
+	Error        string `json:"error,omitempty"`
+}
+
+// Serve exposes /healthz, /readyz and /metrics on addr until ctx is done.
+// ping checks that the database is reachable.
+func Serve(ctx context.Context, addr string, bot *discordgo.Session, ping func() error) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		status := health{Gateway: connected(bot), Database: true}
+		if err := ping(); err != nil {
+			status.Database = false
+			status.Error = err.Error()
+		}
+		lag := time.Since(time.Unix(0, lastSchedulerRun.Load()))
+		status.SchedulerLag = lag.Round(time.Second).String()
+		status.Healthy = status.Gateway && status.Database && lag <= MAX_SCHEDULER_LAG
+
+		w.Header().Set("Content-Type", "application/json")
+		if !status.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(status)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !ready.Load() || !connected(bot) {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		WriteText(w)
+	})
+
+	server := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("Metrics server stopped", "addr", addr, "err", err)
+		}
+	}()
+}
+
+func connected(bot *discordgo.Session) bool {
+	bot.RLock()
+	defer bot.RUnlock()
+	return bot.DataReady
+}
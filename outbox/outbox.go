@@ -0,0 +1,180 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"foulbot/config"
+	"foulbot/data"
+	"foulbot/discord"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Process performs every due outbox entry. Failures are retried with
+// exponential backoff until config.OUTBOX_MAX_ATTEMPTS, after which the entry
+// is left for admins in the dead letters. It stops early if ctx is cancelled.
+func Process(ctx context.Context, bot discord.Session, guildId string) {
+	for {
+		entries := data.DueOutbox(time.Now())
+		if len(entries) == 0 {
+			return
+		}
+		for _, entry := range entries {
+			if ctx.Err() != nil {
+				return
+			}
+			data.ClaimOutbox(entry.Id)
+			err := perform(bot, guildId, entry)
+			if err == nil {
+				data.CompleteOutbox(entry.Id)
+				continue
+			}
+
+			attempts := entry.Attempts + 1
+			dead := attempts >= config.OUTBOX_MAX_ATTEMPTS
+			data.FailOutbox(entry.Id, err, time.Now().Add(Backoff(attempts)), dead)
+			if dead {
+				slog.Error("Gave up on outbox entry", "key", entry.Key, "attempts", attempts, "err", err)
+			} else {
+				slog.Warn("Outbox entry failed, will retry", "key", entry.Key, "attempts", attempts, "retry_in", Backoff(attempts), "err", err)
+			}
+		}
+	}
+}
+
+// Backoff is the delay before retrying an entry that has failed attempts
+// times: doubling from config.OUTBOX_BASE_DELAY up to config.OUTBOX_MAX_DELAY.
+func Backoff(attempts int) time.Duration {
+	delay := config.OUTBOX_BASE_DELAY
+	for i := 1; i < attempts && delay < config.OUTBOX_MAX_DELAY; i++ {
+		delay *= 2
+	}
+	return min(delay, config.OUTBOX_MAX_DELAY)
+}
+
+func perform(bot discord.Session, guildId string, entry data.OutboxEntry) error {
+	payload := entry.Payload
+	switch entry.Kind {
+	case data.OutboxResult:
+		poll, ok := data.EvaluatedPollById(payload.ChannelId, payload.MessageId)
+		if !ok {
+			return fmt.Errorf("poll %s has not been evaluated", payload.MessageId)
+		}
+		var message *discordgo.Message
+		if entry.Claimed {
+			// An earlier attempt may have posted the result before failing
+			found, err := findResult(bot, poll)
+			if err != nil {
+				return fmt.Errorf("failed to look for an earlier result: %v", err)
+			}
+			message = found
+		}
+		if message == nil {
+			sent, err := bot.ChannelMessageSendEmbed(poll.ChannelId, resultEmbed(guildId, poll))
+			if err != nil {
+				return fmt.Errorf("failed to send poll result: %v", err)
+			}
+			message = sent
+		}
+		data.MarkAnnounced(poll.ChannelId, poll.MessageId)
+		payload.ResultMessageId = message.ID
+		data.Enqueue(data.OutboxThread, payload)
+	case data.OutboxThread:
+		_, err := bot.MessageThreadStartComplex(payload.ChannelId, payload.ResultMessageId, &discordgo.ThreadStart{
+			Name:                "Result",
+			AutoArchiveDuration: 60,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to start result thread: %v", err)
+		}
+	case data.OutboxButtons:
+		_, err := bot.ChannelMessageEditComplex(&discordgo.MessageEdit{
+			ID:         payload.MessageId,
+			Channel:    payload.ChannelId,
+			Components: &[]discordgo.MessageComponent{},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to remove vote buttons: %v", err)
+		}
+	default:
+		return fmt.Errorf("unknown outbox kind %q", entry.Kind)
+	}
+	return nil
+}
+
+// findResult looks through the channel's recent messages for a result embed
+// already posted for poll, returning nil if there is none.
+func findResult(bot discord.Session, poll data.EvaluatedPoll) (*discordgo.Message, error) {
+	messages, err := bot.ChannelMessages(poll.ChannelId, 100, "", "", "")
+	if err != nil {
+		return nil, err
+	}
+	link := fmt.Sprintf("/%s/%s)", poll.ChannelId, poll.MessageId)
+	for _, message := range messages {
+		for _, embed := range message.Embeds {
+			for _, field := range embed.Fields {
+				if field.Name == "Reason" && strings.HasSuffix(field.Value, link) {
+					return message, nil
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+func resultEmbed(guildId string, poll data.EvaluatedPoll) *discordgo.MessageEmbed {
+	fields := []*discordgo.MessageEmbedField{
+		{
+			Name:   "Creator",
+			Value:  fmt.Sprintf("<@%s>", poll.CreatorId),
+			Inline: true,
+		},
+		{
+			Name:   "Gainers",
+			Value:  fmt.Sprintf("<@%s>", strings.Join(poll.GainerIds, ">\n<@")),
+			Inline: true,
+		},
+		{
+			Name:   "Points",
+			Value:  fmt.Sprintf("%+d", poll.Points),
+			Inline: true,
+		},
+		{
+			Name:   "Reason",
+			Value:  fmt.Sprintf("[%s](https://discord.com/channels/%s/%s/%s)", poll.Reason, guildId, poll.ChannelId, poll.MessageId),
+			Inline: false,
+		},
+		{
+			Name: "Votes For",
+			Value: func() string {
+				if len(poll.VotesFor) == 0 {
+					return "none"
+				}
+				return fmt.Sprintf("<@%s>", strings.Join(poll.VotesFor, ">\n<@"))
+			}(),
+			Inline: true,
+		},
+		{
+			Name: "Votes Against",
+			Value: func() string {
+				if len(poll.VotesAgainst) == 0 {
+					return "none"
+				}
+				return fmt.Sprintf("<@%s>", strings.Join(poll.VotesAgainst, ">\n<@"))
+			}(),
+			Inline: true,
+		},
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:  map[bool]string{true: "Passed", false: "Failed"}[poll.Passed],
+		Color:  0x417e4b, // Green for passed
+		Fields: fields,
+	}
+	if !poll.Passed {
+		embed.Color = 0xc94543 // Red for failed
+	}
+	return embed
+}
@@ -0,0 +1,186 @@
+package period
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Period is a range of poll expiry times, From inclusive and To exclusive,
+// with a name for embed titles.
+type Period struct {
+	Kind string
+	Name string
+	From time.Time
+	To   time.Time
+}
+
+const (
+	AllTime = "all-time"
+	Year    = "year"
+	Quarter = "quarter"
+	Month   = "month"
+	Week    = "week"
+	Custom  = "custom"
+	// Season periods are configured by admins, so Parse leaves them to the
+	// caller.
+	Season = "season"
+)
+
+// KINDS are the periods /leaderboard offers, in the order they are listed.
+var KINDS = []string{Season, AllTime, Year, Quarter, Month, Week, Custom}
+
+const dateLayout = "2006-01-02"
+
+var quarterPattern = regexp.MustCompile(`^(\d{4})-[Qq]([1-4])$`)
+
+// Parse resolves a period kind around when, or the from/to dates (inclusive,
+// YYYY-MM-DD) for Custom. when may be a year (2024), quarter (2024-Q2), month
+// (2024-05) or day (2024-05-14) and defaults to now. An empty kind is taken
+// from how precise when is, and is Year if when is empty too. Periods are in
+// now's location.
+func Parse(kind, when, from, to string, now time.Time) (Period, error) {
+	if kind == Custom {
+		return custom(from, to, now.Location())
+	}
+	if from != "" || to != "" {
+		return Period{}, fmt.Errorf("from and to only apply to the custom period")
+	}
+	if kind == AllTime {
+		return allTime(), nil
+	}
+	if kind == Season {
+		return Period{}, fmt.Errorf("seasons are looked up by name")
+	}
+
+	anchor, precision := now, Week
+	if when != "" {
+		var err error
+		anchor, precision, err = parseWhen(when, now.Location())
+		if err != nil {
+			return Period{}, err
+		}
+	}
+	if kind == "" {
+		kind = Year
+		if when != "" {
+			kind = precision
+		}
+	}
+	if rank(precision) < rank(kind) {
+		return Period{}, fmt.Errorf("%q is not precise enough for a %s, %s", when, kind, example(kind))
+	}
+	return Around(kind, anchor)
+}
+
+// Around returns the year, quarter, month or week containing t. Weeks start on
+// Monday.
+func Around(kind string, t time.Time) (Period, error) {
+	year, month, day := t.Date()
+	location := t.Location()
+	switch kind {
+	case Year:
+		from := time.Date(year, 1, 1, 0, 0, 0, 0, location)
+		return Period{Kind: kind, Name: strconv.Itoa(year), From: from, To: from.AddDate(1, 0, 0)}, nil
+	case Quarter:
+		quarter := (int(month)-1)/3 + 1
+		from := time.Date(year, time.Month(quarter*3-2), 1, 0, 0, 0, 0, location)
+		return Period{Kind: kind, Name: fmt.Sprintf("Q%d %d", quarter, year), From: from, To: from.AddDate(0, 3, 0)}, nil
+	case Month:
+		from := time.Date(year, month, 1, 0, 0, 0, 0, location)
+		return Period{Kind: kind, Name: from.Format("January 2006"), From: from, To: from.AddDate(0, 1, 0)}, nil
+	case Week:
+		from := time.Date(year, month, day-(int(t.Weekday())+6)%7, 0, 0, 0, 0, location)
+		return Period{Kind: kind, Name: "week of " + from.Format("Jan 2 2006"), From: from, To: from.AddDate(0, 0, 7)}, nil
+	}
+	return Period{}, fmt.Errorf("unknown period %q", kind)
+}
+
+// Key is a short encoding of p for button custom IDs, read back by FromKey.
+func (p Period) Key() string {
+	return p.Kind + "." + strconv.FormatInt(p.From.Unix(), 36) + "." + strconv.FormatInt(p.To.Unix(), 36)
+}
+
+// FromKey decodes a Key in location.
+func FromKey(key string, location *time.Location) (Period, error) {
+	parts := strings.Split(key, ".")
+	if len(parts) != 3 {
+		return Period{}, fmt.Errorf("invalid period key %q", key)
+	}
+	from, err := strconv.ParseInt(parts[1], 36, 64)
+	if err != nil {
+		return Period{}, fmt.Errorf("invalid period key %q", key)
+	}
+	to, err := strconv.ParseInt(parts[2], 36, 64)
+	if err != nil {
+		return Period{}, fmt.Errorf("invalid period key %q", key)
+	}
+	start, end := time.Unix(from, 0).In(location), time.Unix(to, 0).In(location)
+	switch parts[0] {
+	case AllTime:
+		return allTime(), nil
+	case Custom:
+		return custom(start.Format(dateLayout), end.AddDate(0, 0, -1).Format(dateLayout), location)
+	case Season:
+		// The caller knows the season's name
+		return Period{Kind: Season, Name: "season", From: start, To: end}, nil
+	}
+	return Around(parts[0], start)
+}
+
+func allTime() Period {
+	return Period{Kind: AllTime, Name: "all time", From: time.Time{}, To: time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func custom(from, to string, location *time.Location) (Period, error) {
+	if from == "" || to == "" {
+		return Period{}, fmt.Errorf("a custom period needs both from and to dates, e.g. 2024-05-01")
+	}
+	start, err := time.ParseInLocation(dateLayout, from, location)
+	if err != nil {
+		return Period{}, fmt.Errorf("invalid from date %q, expected YYYY-MM-DD", from)
+	}
+	end, err := time.ParseInLocation(dateLayout, to, location)
+	if err != nil {
+		return Period{}, fmt.Errorf("invalid to date %q, expected YYYY-MM-DD", to)
+	}
+	if end.Before(start) {
+		return Period{}, fmt.Errorf("from date %s is after to date %s", from, to)
+	}
+	return Period{Kind: Custom, Name: from + " to " + to, From: start, To: end.AddDate(0, 0, 1)}, nil
+}
+
+// parseWhen returns the start of when and whether it names a year, quarter,
+// month or a day (which is precise enough for a week).
+func parseWhen(when string, location *time.Location) (time.Time, string, error) {
+	if match := quarterPattern.FindStringSubmatch(when); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		quarter, _ := strconv.Atoi(match[2])
+		return time.Date(year, time.Month(quarter*3-2), 1, 0, 0, 0, 0, location), Quarter, nil
+	}
+	for _, layout := range []struct {
+		layout    string
+		precision string
+	}{{dateLayout, Week}, {"2006-01", Month}, {"2006", Year}} {
+		t, err := time.ParseInLocation(layout.layout, when, location)
+		if err == nil {
+			return t, layout.precision, nil
+		}
+	}
+	return time.Time{}, "", fmt.Errorf("invalid date %q, expected 2024, 2024-Q2, 2024-05 or 2024-05-14", when)
+}
+
+func rank(kind string) int {
+	return map[string]int{Year: 0, Quarter: 1, Month: 2, Week: 3}[kind]
+}
+
+func example(kind string) string {
+	return map[string]string{
+		Year:    "e.g. 2024",
+		Quarter: "e.g. 2024-Q2 or 2024-05",
+		Month:   "e.g. 2024-05",
+		Week:    "use a day such as 2024-05-14",
+	}[kind]
+}
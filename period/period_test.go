@@ -0,0 +1,96 @@
+package period
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC) // a Wednesday
+	day := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		kind, when, from, to string
+		name                 string
+		start, end           time.Time
+	}{
+		{"", "", "", "", "2024", day(2024, 1, 1), day(2025, 1, 1)},
+		{"", "2023", "", "", "2023", day(2023, 1, 1), day(2024, 1, 1)},
+		{"", "2023-q4", "", "", "Q4 2023", day(2023, 10, 1), day(2024, 1, 1)},
+		{"", "2023-02", "", "", "February 2023", day(2023, 2, 1), day(2023, 3, 1)},
+		{Quarter, "", "", "", "Q2 2024", day(2024, 4, 1), day(2024, 7, 1)},
+		{Quarter, "2024-08", "", "", "Q3 2024", day(2024, 7, 1), day(2024, 10, 1)},
+		{Month, "", "", "", "May 2024", day(2024, 5, 1), day(2024, 6, 1)},
+		{Week, "", "", "", "week of May 13 2024", day(2024, 5, 13), day(2024, 5, 20)},
+		{Week, "2024-05-19", "", "", "week of May 13 2024", day(2024, 5, 13), day(2024, 5, 20)},
+		{Year, "2022-07-04", "", "", "2022", day(2022, 1, 1), day(2023, 1, 1)},
+		{Custom, "", "2024-05-01", "2024-05-01", "2024-05-01 to 2024-05-01", day(2024, 5, 1), day(2024, 5, 2)},
+	}
+	for _, test := range tests {
+		p, err := Parse(test.kind, test.when, test.from, test.to, now)
+		if err != nil {
+			t.Errorf("Parse(%q, %q, %q, %q): %v", test.kind, test.when, test.from, test.to, err)
+			continue
+		}
+		if p.Name != test.name || !p.From.Equal(test.start) || !p.To.Equal(test.end) {
+			t.Errorf("Parse(%q, %q, %q, %q) = %q %s..%s, want %q %s..%s", test.kind, test.when, test.from, test.to,
+				p.Name, p.From, p.To, test.name, test.start, test.end)
+		}
+	}
+
+	all, err := Parse(AllTime, "", "", "", now)
+	if err != nil || !all.From.Before(day(1970, 1, 1)) || !all.To.After(now) {
+		t.Errorf("Parse(all-time) = %+v, %v", all, err)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	now := time.Now()
+	for _, args := range [][4]string{
+		{Month, "2024", "", ""},
+		{Week, "2024-05", "", ""},
+		{"", "last year", "", ""},
+		{"", "2024-13", "", ""},
+		{Year, "", "2024-01-01", ""},
+		{Custom, "", "2024-01-01", ""},
+		{Custom, "", "2024-02-01", "2024-01-01"},
+		{Custom, "", "01/02/2024", "2024-03-01"},
+		{"fortnight", "", "", ""},
+	} {
+		if p, err := Parse(args[0], args[1], args[2], args[3], now); err == nil {
+			t.Errorf("Parse(%q) = %+v, want an error", args, p)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.Local)
+	for _, kind := range KINDS {
+		if kind == Season {
+			continue
+		}
+		p, err := Parse(kind, "", "2024-02-03", "2024-03-04", now)
+		if kind != Custom {
+			p, err = Parse(kind, "", "", "", now)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := FromKey(p.Key(), time.Local)
+		if err != nil || decoded.Name != p.Name || !decoded.From.Equal(p.From) || !decoded.To.Equal(p.To) {
+			t.Errorf("FromKey(%q) = %+v, %v, want %+v", p.Key(), decoded, err, p)
+		}
+	}
+}
+
+func TestSeasonKey(t *testing.T) {
+	p := Period{Kind: Season, Name: "Spring league", From: time.Unix(1700000000, 0), To: time.Unix(1710000000, 0)}
+	decoded, err := FromKey(p.Key(), time.Local)
+	if err != nil || decoded.Kind != Season || !decoded.From.Equal(p.From) || !decoded.To.Equal(p.To) {
+		t.Errorf("FromKey(%q) = %+v, %v, want the season's range", p.Key(), decoded, err)
+	}
+	if _, err := Parse(Season, "Spring league", "", "", time.Now()); err == nil {
+		t.Error("expected Parse to leave seasons to the caller")
+	}
+}
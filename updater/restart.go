@@ -0,0 +1,123 @@
+package updater
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// healthEnv carries the path of the marker file a freshly updated process
+// writes once it is up, see ReportHealthy.
+const healthEnv = "FOULBOT_HEALTH_MARKER"
+
+// executable is the binary Update replaces.
+func executable() (string, error) {
+	if targetPath != "" {
+		return targetPath, nil
+	}
+	path, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(path)
+}
+
+// oldPath is where Update keeps the previous binary for Rollback.
+func oldPath(path string) string {
+	return filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".old")
+}
+
+// StartVerified starts the installed binary with the current arguments and
+// waits up to timeout for it to call ReportHealthy. If it exits or stays
+// silent, it is killed, the previous binary is put back and an error is
+// returned; the caller is still running that binary and can carry on.
+func StartVerified(timeout time.Duration) error {
+	path, err := executable()
+	if err != nil {
+		return err
+	}
+
+	marker, err := os.CreateTemp("", "foulbot-health-*")
+	if err != nil {
+		return err
+	}
+	marker.Close()
+	os.Remove(marker.Name())
+	defer os.Remove(marker.Name())
+
+	cmd := command(path)
+	cmd.Env = append(os.Environ(), healthEnv+"="+marker.Name())
+	if err := cmd.Start(); err != nil {
+		return errors.Join(fmt.Errorf("failed to start new version: %v", err), Rollback())
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	deadline := time.After(timeout)
+	poll := time.NewTicker(500 * time.Millisecond)
+	defer poll.Stop()
+	for {
+		select {
+		case <-poll.C:
+			if _, err := os.Stat(marker.Name()); err == nil {
+				return nil
+			}
+		case err := <-exited:
+			return errors.Join(fmt.Errorf("new version exited during startup: %v", err), Rollback())
+		case <-deadline:
+			cmd.Process.Kill()
+			<-exited
+			return errors.Join(fmt.Errorf("new version did not report healthy within %s", timeout), Rollback())
+		}
+	}
+}
+
+// ReportHealthy tells the process that started this one via StartVerified
+// that startup succeeded. It does nothing when not started by an update.
+func ReportHealthy() error {
+	marker := os.Getenv(healthEnv)
+	if marker == "" {
+		return nil
+	}
+	os.Unsetenv(healthEnv)
+	return os.WriteFile(marker, nil, 0o600)
+}
+
+// Rollback puts the binary saved by the last Update back in place.
+func Rollback() error {
+	path, err := executable()
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(path, path+".failed"); err != nil {
+		return fmt.Errorf("rollback failed: %v", err)
+	}
+	if err := os.Rename(oldPath(path), path); err != nil {
+		os.Rename(path+".failed", path)
+		return fmt.Errorf("rollback failed: %v", err)
+	}
+	os.Remove(path + ".failed")
+	return nil
+}
+
+// Restart starts the installed binary with the current arguments without
+// waiting on it.
+func Restart() error {
+	path, err := executable()
+	if err != nil {
+		return err
+	}
+	return command(path).Start()
+}
+
+func command(path string) *exec.Cmd {
+	cmd := exec.Command(path, os.Args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	return cmd
+}
@@ -0,0 +1,190 @@
+package updater
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"foulbot/config"
+	"io"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/inconshreveable/go-update"
+)
+
+// A release publishes, next to every binary, <binary>.sha256 (sha256sum
+// output), <binary>.sig (base64 ed25519 signature of the release's version,
+// the binary's name and its checksum, see signedMessage) and a version.txt
+// naming the release. version.txt itself is not signed: the version it names
+// only passes verification if it is the one the binary was signed as.
+
+// targetPath is the file that gets replaced. Empty means the running
+// executable; tests point it somewhere harmless.
+var targetPath = ""
+
+var ErrNotNewer = errors.New("release is not newer than the running version")
+
+// BinaryName is the release asset built for this platform.
+func BinaryName() string {
+	extension := map[string]string{"windows": ".exe"}[runtime.GOOS]
+	return fmt.Sprintf("foulbot-%s-%s%s", runtime.GOOS, runtime.GOARCH, extension)
+}
+
+// LatestVersion returns the version of the latest release at releasesURL.
+func LatestVersion(releasesURL string) (string, error) {
+	version, err := fetch(strings.TrimSuffix(releasesURL, "/") + "/latest/download/version.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to get release version: %v", err)
+	}
+	return strings.TrimSpace(string(version)), nil
+}
+
+// Update downloads a release from releasesURL, verifies its checksum and
+// signature against config.UPDATE_PUBLIC_KEY and replaces the running binary,
+// keeping the previous one for Rollback. An empty version means the latest
+// release, which is refused with ErrNotNewer if it is not newer than
+// config.VERSION unless force is set; a pinned version is always installed.
+// It returns the version that was installed.
+func Update(releasesURL string, version string, force bool) (string, error) {
+	publicKey, err := base64.StdEncoding.DecodeString(config.UPDATE_PUBLIC_KEY)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return "", fmt.Errorf("this build has no valid update public key")
+	}
+
+	to := version
+	base := strings.TrimSuffix(releasesURL, "/") + "/download/" + version + "/"
+	if version == "" {
+		to, err = LatestVersion(releasesURL)
+		if err != nil {
+			return "", err
+		}
+		if !force && CompareVersions(to, config.VERSION) <= 0 {
+			return to, ErrNotNewer
+		}
+		base = strings.TrimSuffix(releasesURL, "/") + "/latest/download/"
+	}
+
+	checksumFile, err := fetch(base + BinaryName() + ".sha256")
+	if err != nil {
+		return to, fmt.Errorf("failed to get checksum: %v", err)
+	}
+	fields := strings.Fields(string(checksumFile))
+	if len(fields) == 0 {
+		return to, fmt.Errorf("checksum file is empty")
+	}
+	checksum, err := hex.DecodeString(fields[0])
+	if err != nil {
+		return to, fmt.Errorf("invalid checksum: %v", err)
+	}
+
+	signatureFile, err := fetch(base + BinaryName() + ".sig")
+	if err != nil {
+		return to, fmt.Errorf("failed to get signature: %v", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signatureFile)))
+	if err != nil {
+		return to, fmt.Errorf("invalid signature: %v", err)
+	}
+
+	binary, err := fetch(base + BinaryName())
+	if err != nil {
+		return to, fmt.Errorf("failed to download update: %v", err)
+	}
+
+	path, err := executable()
+	if err != nil {
+		return to, err
+	}
+	err = update.Apply(bytes.NewReader(binary), update.Options{
+		TargetPath:  path,
+		OldSavePath: oldPath(path),
+		Checksum:    checksum,
+		Signature:   signature,
+		PublicKey:   ed25519.PublicKey(publicKey),
+		Verifier:    ed25519Verifier{version: to, name: BinaryName()},
+		Hash:        crypto.SHA256,
+	})
+	if err != nil {
+		return to, fmt.Errorf("failed to apply update: %v", err)
+	}
+	return to, nil
+}
+
+// Sign produces the .sha256 and .sig contents for the binary called name in
+// release version.
+func Sign(privateKey ed25519.PrivateKey, version string, name string, binary []byte) (checksumFile []byte, signatureFile []byte) {
+	checksum := sha256.Sum256(binary)
+	checksumFile = []byte(fmt.Sprintf("%x  %s\n", checksum, name))
+	signature := ed25519.Sign(privateKey, signedMessage(version, name, checksum[:]))
+	signatureFile = []byte(base64.StdEncoding.EncodeToString(signature) + "\n")
+	return checksumFile, signatureFile
+}
+
+// signedMessage binds a binary's checksum to its release version and asset
+// name, so a validly signed binary cannot be served as another version or
+// platform.
+func signedMessage(version string, name string, checksum []byte) []byte {
+	return []byte(fmt.Sprintf("%s\n%s\n%x\n", version, name, checksum))
+}
+
+// CompareVersions orders release tags such as "12", "v12" or "1.2.3"
+// numerically, returning -1, 0 or 1. Unparseable parts, including an empty
+// development version, count as 0.
+func CompareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// ed25519Verifier checks a signature made by Sign for the binary called name
+// in release version.
+type ed25519Verifier struct {
+	version string
+	name    string
+}
+
+func (v ed25519Verifier) VerifySignature(checksum, signature []byte, _ crypto.Hash, publicKey crypto.PublicKey) error {
+	key, ok := publicKey.(ed25519.PublicKey)
+	if !ok {
+		return errors.New("not a valid ed25519 public key")
+	}
+	if !ed25519.Verify(key, signedMessage(v.version, v.name, checksum), signature) {
+		return fmt.Errorf("invalid ed25519 signature for %s version %s", v.name, v.version)
+	}
+	return nil
+}
+
+func fetch(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
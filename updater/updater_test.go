@@ -0,0 +1,197 @@
+package updater
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"foulbot/config"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// releaseServer stands in for GitHub releases, serving assets by URL path.
+func releaseServer(t *testing.T, assets map[string][]byte) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asset, ok := assets[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(asset)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// signedRelease returns the assets of a release published under
+// /latest/download, signed with a fresh key that becomes the trusted one.
+func signedRelease(t *testing.T, version string, binary []byte) map[string][]byte {
+	t.Helper()
+	return signedReleaseIn(t, "/latest/download/", version, binary)
+}
+
+func signedReleaseIn(t *testing.T, dir string, version string, binary []byte) map[string][]byte {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.UPDATE_PUBLIC_KEY = base64.StdEncoding.EncodeToString(publicKey)
+
+	checksum, signature := Sign(privateKey, version, BinaryName(), binary)
+	return map[string][]byte{
+		dir + "version.txt":            []byte(version + "\n"),
+		dir + BinaryName():             binary,
+		dir + BinaryName() + ".sha256": checksum,
+		dir + BinaryName() + ".sig":    signature,
+	}
+}
+
+func useTarget(t *testing.T, version string) string {
+	t.Helper()
+	targetPath = filepath.Join(t.TempDir(), "foulbot")
+	if err := os.WriteFile(targetPath, []byte("old binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	config.VERSION = version
+	t.Cleanup(func() { targetPath = "" })
+	return targetPath
+}
+
+func assertTarget(t *testing.T, path string, want string) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("target contains %q, want %q", got, want)
+	}
+}
+
+func TestUpdateAppliesSignedRelease(t *testing.T) {
+	target := useTarget(t, "4")
+	url := releaseServer(t, signedRelease(t, "5", []byte("new binary")))
+
+	to, err := Update(url, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if to != "5" {
+		t.Errorf("updated to %q, want 5", to)
+	}
+	assertTarget(t, target, "new binary")
+}
+
+func TestUpdateRejectsTamperedBinary(t *testing.T) {
+	target := useTarget(t, "4")
+	assets := signedRelease(t, "5", []byte("new binary"))
+	assets["/latest/download/"+BinaryName()] = []byte("evil binary")
+	url := releaseServer(t, assets)
+
+	if _, err := Update(url, "", false); err == nil {
+		t.Fatal("expected checksum mismatch")
+	}
+	assertTarget(t, target, "old binary")
+}
+
+func TestUpdateRejectsWrongSigner(t *testing.T) {
+	target := useTarget(t, "4")
+	assets := signedRelease(t, "5", []byte("new binary"))
+	// Trust a different key than the one the release was signed with
+	signedRelease(t, "5", nil)
+	url := releaseServer(t, assets)
+
+	if _, err := Update(url, "", false); err == nil {
+		t.Fatal("expected signature failure")
+	}
+	assertTarget(t, target, "old binary")
+}
+
+func TestUpdateRejectsRelabelledVersion(t *testing.T) {
+	target := useTarget(t, "4")
+	// An old, validly signed release served as if it were newer
+	assets := signedRelease(t, "3", []byte("old release"))
+	assets["/latest/download/version.txt"] = []byte("5\n")
+	url := releaseServer(t, assets)
+
+	if _, err := Update(url, "", false); err == nil {
+		t.Fatal("expected signature failure")
+	}
+	assertTarget(t, target, "old binary")
+}
+
+func TestUpdateRefusesDowngradeUnlessForced(t *testing.T) {
+	target := useTarget(t, "6")
+	url := releaseServer(t, signedRelease(t, "5", []byte("new binary")))
+
+	if _, err := Update(url, "", false); err != ErrNotNewer {
+		t.Fatalf("got %v, want ErrNotNewer", err)
+	}
+	assertTarget(t, target, "old binary")
+
+	if _, err := Update(url, "", true); err != nil {
+		t.Fatal(err)
+	}
+	assertTarget(t, target, "new binary")
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"5", "4", 1},
+		{"v5", "5", 0},
+		{"9", "10", -1},
+		{"1.2.10", "1.2.9", 1},
+		{"1", "", 1},
+	}
+	for _, test := range tests {
+		if got := CompareVersions(test.a, test.b); got != test.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestRollbackRestoresPreviousBinary(t *testing.T) {
+	target := useTarget(t, "4")
+	url := releaseServer(t, signedRelease(t, "5", []byte("new binary")))
+
+	if _, err := Update(url, "", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	assertTarget(t, target, "old binary")
+}
+
+func TestUpdatePinnedVersion(t *testing.T) {
+	target := useTarget(t, "6")
+	assets := signedRelease(t, "6", []byte("latest binary"))
+	for path, asset := range signedReleaseIn(t, "/download/3/", "3", []byte("pinned binary")) {
+		assets[path] = asset
+	}
+	url := releaseServer(t, assets)
+
+	latest, err := LatestVersion(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest != "6" {
+		t.Errorf("latest version %q, want 6", latest)
+	}
+
+	to, err := Update(url, "3", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if to != "3" {
+		t.Errorf("updated to %q, want 3", to)
+	}
+	assertTarget(t, target, "pinned binary")
+}